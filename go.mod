@@ -6,6 +6,7 @@ require (
 	github.com/Pallinder/go-randomdata v1.2.0
 	github.com/dlclark/regexp2 v1.4.0 // indirect
 	github.com/go-chi/chi v1.5.4
+	github.com/go-playground/form v3.1.4+incompatible
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/goplaid/web v1.1.9
 	github.com/hashicorp/golang-lru v0.5.4 // indirect
@@ -18,7 +19,7 @@ require (
 	github.com/manifoldco/promptui v0.8.0
 	github.com/mattn/go-colorable v0.1.9 // indirect
 	github.com/mattn/go-isatty v0.0.14 // indirect
-	github.com/microcosm-cc/bluemonday v1.0.15 // indirect
+	github.com/microcosm-cc/bluemonday v1.0.15
 	github.com/ory/ladon v1.2.0
 	github.com/pborman/uuid v1.2.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
@@ -2,8 +2,9 @@ package presets
 
 type ActionBuilder struct {
 	NameLabel
-	updateFunc ActionUpdateFunc
-	compFunc   ActionComponentFunc
+	updateFunc         ActionUpdateFunc
+	updateMatchingFunc ActionUpdateMatchingFunc
+	compFunc           ActionComponentFunc
 }
 
 func (b *ListingBuilder) BulkAction(name string) (r *ActionBuilder) {
@@ -31,6 +32,13 @@ func (b *ActionBuilder) UpdateFunc(v ActionUpdateFunc) (r *ActionBuilder) {
 	return b
 }
 
+// UpdateMatchingFunc makes the bulk action available when all the records matching the listing
+// search and filters are selected, that are passed as searchParams without pagination instead of ids
+func (b *ActionBuilder) UpdateMatchingFunc(v ActionUpdateMatchingFunc) (r *ActionBuilder) {
+	b.updateMatchingFunc = v
+	return b
+}
+
 func (b *ActionBuilder) Label(v string) (r *ActionBuilder) {
 	b.label = v
 	return b
@@ -1,12 +1,32 @@
 package actions
 
 const (
-	DrawerNew          = "presets_DrawerNew"
-	DrawerEdit         = "presets_DrawerEdit"
-	DrawerAction       = "presets_DrawerAction"
-	DeleteConfirmation = "presets_DeleteConfirmation"
-	Update             = "presets_Update"
-	DoAction           = "presets_DoAction"
-	DoDelete           = "presets_DoDelete"
-	DoBulkAction       = "presets_DoBulkAction"
+	DrawerNew               = "presets_DrawerNew"
+	DrawerEdit              = "presets_DrawerEdit"
+	DrawerAction            = "presets_DrawerAction"
+	DeleteConfirmation      = "presets_DeleteConfirmation"
+	Update                  = "presets_Update"
+	DoAction                = "presets_DoAction"
+	DoDelete                = "presets_DoDelete"
+	DoBulkAction            = "presets_DoBulkAction"
+	DrawerImport            = "presets_DrawerImport"
+	ImportUpload            = "presets_ImportUpload"
+	ImportPreview           = "presets_ImportPreview"
+	DoImport                = "presets_DoImport"
+	SaveViewDialog          = "presets_SaveViewDialog"
+	DoSaveView              = "presets_DoSaveView"
+	DoDeleteView            = "presets_DoDeleteView"
+	InlineEdit              = "presets_InlineEdit"
+	CancelInlineEdit        = "presets_CancelInlineEdit"
+	DoInlineEdit            = "presets_DoInlineEdit"
+	UpdateListingColumns    = "presets_UpdateListingColumns"
+	MoveKanbanCard          = "presets_MoveKanbanCard"
+	RescheduleCalendarEvent = "presets_RescheduleCalendarEvent"
+	LoadTreeChildren        = "presets_LoadTreeChildren"
+	MoveTreeNode            = "presets_MoveTreeNode"
+	BelongsToOptions        = "presets_BelongsToOptions"
+	ManyToManyOptions       = "presets_ManyToManyOptions"
+	AddHasManyRow           = "presets_AddHasManyRow"
+	RemoveHasManyRow        = "presets_RemoveHasManyRow"
+	MoveHasManyRow          = "presets_MoveHasManyRow"
 )
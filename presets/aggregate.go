@@ -0,0 +1,158 @@
+package presets
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/goplaid/web"
+	s "github.com/goplaid/x/stripeui"
+	h "github.com/theplant/htmlgo"
+)
+
+type listingAggregate struct {
+	fieldName string
+	function  AggregateFunction
+}
+
+// Aggregate shows the aggregates of the field in the table footer,
+// that are computed over all the records matching the search, not only the current page
+func (b *ListingBuilder) Aggregate(fieldName string, fs ...AggregateFunction) (r *ListingBuilder) {
+	for _, f := range fs {
+		b.aggregates = append(b.aggregates, &listingAggregate{fieldName: fieldName, function: f})
+	}
+	return b
+}
+
+// GroupBy orders the records by the field first, and renders a header row with the subtotals of the aggregates
+// before every group, it's ignored with cursor pagination that orders by its own columns
+func (b *ListingBuilder) GroupBy(fieldName string) (r *ListingBuilder) {
+	b.groupBy = fieldName
+	return b
+}
+
+func (b *ListingBuilder) Aggregator(v AggregateFunc) (r *ListingBuilder) {
+	b.aggregator = v
+	return b
+}
+
+func (b *ListingBuilder) isGrouped() bool {
+	return len(b.groupBy) > 0 && !b.cursorPagination
+}
+
+func (b *ListingBuilder) aggregateParams(groupBy string) (r *AggregateParams) {
+	r = &AggregateParams{}
+	if len(groupBy) > 0 {
+		r.GroupBy = sortKey(groupBy)
+	}
+	for _, a := range b.aggregates {
+		r.Aggregates = append(r.Aggregates, &Aggregate{
+			Column:   sortKey(a.fieldName),
+			Function: a.function,
+		})
+	}
+	return
+}
+
+// aggregate adds the footer row of the totals and the group header rows of the subtotals to the data table
+func (b *ListingBuilder) aggregate(dataTable *s.DataTableBuilder, firstColumn string, searchParams *SearchParams, msgr *Messages, ctx *web.EventContext) (err error) {
+	if len(b.aggregates) == 0 && !b.isGrouped() {
+		return
+	}
+	// the aggregates are not shown if the data operator is not an Aggregator
+	hasAggregates := len(b.aggregates) > 0 && b.aggregator != nil
+
+	if hasAggregates {
+		var totals []*AggregateResult
+		totals, err = b.aggregator(b.mb.newModel(), searchParams, b.aggregateParams(""), ctx)
+		if err != nil {
+			return
+		}
+
+		var total *AggregateResult
+		if len(totals) > 0 {
+			total = totals[0]
+		}
+		dataTable.FootCellFunc(func(columnName string, ctx *web.EventContext) h.HTMLComponent {
+			return b.aggregateCell(msgr, columnName, firstColumn, msgr.AggregateTotal, total)
+		})
+	}
+
+	if !b.isGrouped() {
+		return
+	}
+
+	subtotals := map[string]*AggregateResult{}
+	if hasAggregates {
+		var groups []*AggregateResult
+		groups, err = b.aggregator(b.mb.newModel(), searchParams, b.aggregateParams(b.groupBy), ctx)
+		if err != nil {
+			return
+		}
+		for _, g := range groups {
+			subtotals[fmt.Sprint(g.Group)] = g
+		}
+	}
+
+	label := b.groupBy
+	if f := b.getField(b.groupBy); f != nil {
+		label = b.columnLabel(f, ctx)
+	}
+	dataTable.GroupBy(b.groupBy, func(group interface{}, columnName string, ctx *web.EventContext) h.HTMLComponent {
+		return b.aggregateCell(msgr, columnName, firstColumn, fmt.Sprintf("%s: %v", label, group), subtotals[fmt.Sprint(group)])
+	})
+	return
+}
+
+// aggregateCell shows the label in the first column, and the values of the aggregates of the column
+func (b *ListingBuilder) aggregateCell(msgr *Messages, columnName string, firstColumn string, label string, result *AggregateResult) h.HTMLComponent {
+	var children []h.HTMLComponent
+	if columnName == firstColumn {
+		children = append(children, h.Div(h.Text(label)))
+	}
+
+	for i, a := range b.aggregates {
+		if a.fieldName != columnName || result == nil || i >= len(result.Values) {
+			continue
+		}
+		children = append(children, h.Div(
+			h.Span(aggregateFunctionLabel(msgr, a.function)).Class("grey--text mr-1"),
+			h.Text(formatAggregateValue(result.Values[i])),
+		))
+	}
+	return h.Td(children...).Class("font-weight-medium")
+}
+
+func aggregateFunctionLabel(msgr *Messages, f AggregateFunction) string {
+	switch f {
+	case AggregateSum:
+		return msgr.AggregateSum
+	case AggregateAvg:
+		return msgr.AggregateAvg
+	case AggregateCount:
+		return msgr.AggregateCount
+	case AggregateMin:
+		return msgr.AggregateMin
+	case AggregateMax:
+		return msgr.AggregateMax
+	}
+	return string(f)
+}
+
+// formatAggregateValue rounds averages to two decimals, null of empty results is shown as -
+func formatAggregateValue(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return "-"
+	case float32:
+		return formatAggregateValue(float64(vv))
+	case float64:
+		return strconv.FormatFloat(math.Round(vv*100)/100, 'f', -1, 64)
+	case string:
+		if f, err := strconv.ParseFloat(vv, 64); err == nil && strings.Contains(vv, ".") {
+			return formatAggregateValue(f)
+		}
+	}
+	return fmt.Sprint(v)
+}
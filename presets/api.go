@@ -1,8 +1,13 @@
 package presets
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strings"
 
 	"github.com/goplaid/web"
 	"github.com/goplaid/x/vuetifyx"
@@ -11,9 +16,11 @@ import (
 
 type ComponentFunc func(ctx *web.EventContext) h.HTMLComponent
 type FieldComponentFunc func(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent
+type FieldExportFunc func(obj interface{}, field *FieldContext, ctx *web.EventContext) (r interface{})
 
 type ActionComponentFunc func(selectedIds []string, ctx *web.EventContext) h.HTMLComponent
 type ActionUpdateFunc func(selectedIds []string, ctx *web.EventContext) (err error)
+type ActionUpdateMatchingFunc func(searchParams *SearchParams, ctx *web.EventContext) (err error)
 
 type MessagesFunc func(r *http.Request) *Messages
 
@@ -25,11 +32,27 @@ type DataOperator interface {
 	Delete(obj interface{}, id string, ctx *web.EventContext) (err error)
 }
 
+// Aggregator is an optional interface of DataOperator, listings show the aggregates only if it's implemented
+type Aggregator interface {
+	Aggregate(obj interface{}, params *SearchParams, aggParams *AggregateParams, ctx *web.EventContext) (r []*AggregateResult, err error)
+}
+
+// ParamsSaver is an optional interface of DataOperator, editing forms save objects with it if it's implemented
+type ParamsSaver interface {
+	SaveWithParams(obj interface{}, id string, params *SaveParams, ctx *web.EventContext) (err error)
+}
+
+// ParamsFetcher is an optional interface of DataOperator, editing forms fetch objects with it if it's implemented
+type ParamsFetcher interface {
+	FetchWithParams(obj interface{}, id string, params *FetchParams, ctx *web.EventContext) (r interface{}, err error)
+}
+
 type SetterFunc func(obj interface{}, ctx *web.EventContext)
 type FieldSetterFunc func(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error)
 type ValidateFunc func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors)
 
 type SearchFunc func(model interface{}, params *SearchParams, ctx *web.EventContext) (r interface{}, totalCount int, err error)
+type AggregateFunc func(model interface{}, params *SearchParams, aggParams *AggregateParams, ctx *web.EventContext) (r []*AggregateResult, err error)
 type FetchFunc func(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error)
 type SaveFunc func(obj interface{}, id string, ctx *web.EventContext) (err error)
 type DeleteFunc func(obj interface{}, id string, ctx *web.EventContext) (err error)
@@ -51,8 +74,134 @@ type SQLCondition struct {
 type SearchParams struct {
 	KeywordColumns []string
 	Keyword        string
-	SQLConditions  []*SQLCondition
-	PerPage        int64
-	Page           int64
-	OrderBy        string
+	// FullTextSearch searches the keyword in the keyword columns instead of LIKE if it's not nil
+	FullTextSearch FullTextSearch
+	// RankByKeyword orders by the rank of the full-text search first, then the OrderBy
+	RankByKeyword bool
+	SQLConditions []*SQLCondition
+	PerPage       int64
+	Page          int64
+	OrderBy       string
+	Cursor        *SearchCursor
+	// Associations are the many-to-many fields like Tags loaded with the records
+	Associations []string
+}
+
+// SaveParams are the params of saving an object,
+// Fields are the fields set by the form, they are updated even if they are set to zero values,
+// Associations are the has-many fields like CreditCards whose children are saved with the object,
+// and the children removed from them are deleted, and the many-to-many fields like Tags whose associations are replaced,
+// LockVersion makes the update fail with ErrLockConflict if the version isn't the saved one
+type SaveParams struct {
+	Fields       []string
+	Associations []string
+	LockVersion  *LockVersionParams
+}
+
+// LockVersionParams is the version field of the optimistic locking, and the version that the object is fetched with
+type LockVersionParams struct {
+	Field   string
+	Version interface{}
+}
+
+// SQLCondition is the condition that the version of the column is the fetched one, the zero version is also null
+func (p *LockVersionParams) SQLCondition(column string) *SQLCondition {
+	if reflect.ValueOf(p.Version).IsZero() {
+		return &SQLCondition{Query: fmt.Sprintf("(%s = ? OR %s IS NULL)", column, column), Args: []interface{}{p.Version}}
+	}
+	return &SQLCondition{Query: fmt.Sprintf("%s = ?", column), Args: []interface{}{p.Version}}
+}
+
+// ErrLockConflict is the error of saving an object that's updated by others after it's fetched
+var ErrLockConflict = errors.New("lock version conflict")
+
+// FetchParams are the params of fetching an object, Associations are the has-many and many-to-many fields loaded with it
+type FetchParams struct {
+	Associations []string
+}
+
+type AggregateFunction string
+
+const (
+	AggregateSum   AggregateFunction = "SUM"
+	AggregateAvg   AggregateFunction = "AVG"
+	AggregateCount AggregateFunction = "COUNT"
+	AggregateMin   AggregateFunction = "MIN"
+	AggregateMax   AggregateFunction = "MAX"
+)
+
+type Aggregate struct {
+	Column   string
+	Function AggregateFunction
+}
+
+// AggregateParams are the aggregates to compute over all the records matching the search params,
+// per value of the GroupBy column if it's not empty
+type AggregateParams struct {
+	Aggregates []*Aggregate
+	GroupBy    string
+}
+
+// AggregateResult has the values in the order of the aggregates, Group is the value of the GroupBy column
+type AggregateResult struct {
+	Group  interface{}
+	Values []interface{}
+}
+
+// Expression is the sql of the aggregate like SUM(amount), COUNT(*) for COUNT without a column
+func (a *Aggregate) Expression() string {
+	column := a.Column
+	if len(column) == 0 {
+		column = "*"
+	}
+	return fmt.Sprintf("%s(%s)", a.Function, column)
+}
+
+// SelectSQL is the select clause of the aggregates, the GroupBy column is the first if it's set
+func (p *AggregateParams) SelectSQL() string {
+	var selects []string
+	if len(p.GroupBy) > 0 {
+		selects = append(selects, p.GroupBy)
+	}
+	for _, a := range p.Aggregates {
+		selects = append(selects, a.Expression())
+	}
+	return strings.Join(selects, ", ")
+}
+
+// ScanResults reads the results from the rows of the query selecting SelectSQL
+func (p *AggregateParams) ScanResults(rows *sql.Rows) (r []*AggregateResult, err error) {
+	grouped := len(p.GroupBy) > 0
+	columns := len(p.Aggregates)
+	if grouped {
+		columns++
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, columns)
+		ptrs := make([]interface{}, columns)
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		err = rows.Scan(ptrs...)
+		if err != nil {
+			return
+		}
+
+		// drivers like postgres return numeric and text as bytes
+		for i, v := range values {
+			if bs, ok := v.([]byte); ok {
+				values[i] = string(bs)
+			}
+		}
+
+		result := &AggregateResult{Values: values}
+		if grouped {
+			result.Group = values[0]
+			result.Values = values[1:]
+		}
+		r = append(r, result)
+	}
+	err = rows.Err()
+	return
 }
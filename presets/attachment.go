@@ -0,0 +1,237 @@
+package presets
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/goplaid/web"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/rs/xid"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// Attachment is the field type of the uploaded files, the file is stored in the AttachmentStorage of the builder
+// by Key, and the field is stored in a text column as json
+type Attachment struct {
+	Key         string `json:"key"`
+	FileName    string `json:"fileName"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+}
+
+func (a Attachment) Value() (driver.Value, error) {
+	if len(a.Key) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *Attachment) Scan(v interface{}) error {
+	var b []byte
+	switch vv := v.(type) {
+	case nil:
+	case string:
+		b = []byte(vv)
+	case []byte:
+		b = vv
+	default:
+		return fmt.Errorf("can't scan %T into Attachment", v)
+	}
+	*a = Attachment{}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, a)
+}
+
+func (Attachment) GormDataType() string {
+	return "text"
+}
+
+// AttachmentStorage stores the files of the Attachment fields, implement it for the storages other than
+// the local file system and s3 in the storage package
+type AttachmentStorage interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	Get(key string) (body io.ReadCloser, err error)
+}
+
+// AttachmentStorage is required for the Attachment fields, the downloads of them are served by the builder,
+// the files replaced or removed in the forms are kept in the storage
+func (b *Builder) AttachmentStorage(v AttachmentStorage) (r *Builder) {
+	b.attachmentStorage = v
+	return b
+}
+
+var attachmentType = reflect.TypeOf(Attachment{})
+
+const attachmentsPath = "/attachments"
+
+// AttachmentHref is the url that downloads the Attachment field of the record
+func (b *ModelInfo) AttachmentHref(id string, fieldName string) string {
+	return fmt.Sprintf("%s%s?%s", b.ListingHref(), attachmentsPath, url.Values{"id": {id}, "field": {fieldName}}.Encode())
+}
+
+func attachmentFileFieldName(fieldName string) string {
+	return "__Attachment_" + fieldName
+}
+
+func attachmentRemoveFieldName(fieldName string) string {
+	return "__AttachmentRemove_" + fieldName
+}
+
+func attachmentLink(obj interface{}, field *FieldContext) h.HTMLComponent {
+	a := field.Value(obj).(Attachment)
+	if len(a.Key) == 0 {
+		return nil
+	}
+	id := (*ModelBuilder)(field.ModelInfo).objectID(obj)
+	return h.A().Text(a.FileName).Href(field.ModelInfo.AttachmentHref(id, field.Name)).Target("_blank")
+}
+
+func cfAttachmentTd(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return h.Td(attachmentLink(obj, field))
+}
+
+func cfAttachmentDetail(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	var size h.HTMLComponent
+	if a := field.Value(obj).(Attachment); len(a.Key) > 0 {
+		size = h.Span(fmt.Sprintf(" (%s)", formatFileSize(a.Size))).Class("grey--text")
+	}
+	return h.Div(
+		h.Div(h.Text(field.Label)).Class("text-caption grey--text"),
+		h.Div(attachmentLink(obj, field), size),
+	).Class("mb-4")
+}
+
+func cfAttachmentInput(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+
+	var current h.HTMLComponent
+	if a := field.Value(obj).(Attachment); len(a.Key) > 0 {
+		current = h.Div(
+			VIcon("attach_file").Small(true),
+			attachmentLink(obj, field),
+			h.Span(fmt.Sprintf(" (%s)", formatFileSize(a.Size))).Class("grey--text"),
+			VCheckbox().FieldName(attachmentRemoveFieldName(field.Name)).Label(msgr.AttachmentRemove).Dense(true).HideDetails(true),
+		).Class("mb-2")
+	}
+
+	var errs string
+	if len(field.Errors) > 0 {
+		errs = field.Errors[0]
+	}
+	return h.Div(
+		current,
+		VFileInput().
+			FieldName(attachmentFileFieldName(field.Name)).
+			Label(field.Label).
+			ErrorMessages(errs),
+	).Class("mb-4")
+}
+
+// attachmentSetter stores the uploaded file and sets it to the field, the field is removed if the remove checkbox is
+// checked, and not changed if nothing is uploaded
+func attachmentSetter(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error) {
+	f, fh, err1 := ctx.R.FormFile(attachmentFileFieldName(field.Name))
+	if err1 != nil {
+		if ctx.R.FormValue(attachmentRemoveFieldName(field.Name)) == "true" {
+			return reflectutils.Set(obj, field.Name, Attachment{})
+		}
+		return
+	}
+	defer f.Close()
+
+	storage := field.ModelInfo.p.attachmentStorage
+	if storage == nil {
+		panic("presets.New().AttachmentStorage(...) required")
+	}
+
+	contentType := fh.Header.Get("Content-Type")
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+	a := Attachment{
+		Key:         fmt.Sprintf("%s/%s%s", field.ModelInfo.uriName, xid.New(), attachmentExt(fh.Filename)),
+		FileName:    fh.Filename,
+		Size:        fh.Size,
+		ContentType: contentType,
+	}
+	if err = storage.Put(a.Key, f, a.Size, a.ContentType); err != nil {
+		return
+	}
+	return reflectutils.Set(obj, field.Name, a)
+}
+
+var attachmentExtRegexp = regexp.MustCompile(`^\.[A-Za-z0-9.]+$`)
+
+// attachmentExt is the extension of the file name kept in the key, it's dropped if it has the characters
+// like ? and % that are not the same in the urls of the storages
+func attachmentExt(filename string) string {
+	ext := path.Ext(filename)
+	if !attachmentExtRegexp.MatchString(ext) {
+		return ""
+	}
+	return ext
+}
+
+// attachmentHandler serves the file of the Attachment field to the users that can get the record
+func (b *ModelBuilder) attachmentHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := &web.EventContext{R: r, W: w, Event: &web.Event{}}
+	fieldName := r.URL.Query().Get("field")
+	if reflectutils.GetType(b.model, fieldName) != attachmentType {
+		http.NotFound(w, r)
+		return
+	}
+
+	obj, err := b.editing.fetcher(b.newModel(), r.URL.Query().Get("id"), ctx)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if b.Info().Verifier().Do(PermGet).ObjectOn(obj).SnakeOn(fieldName).WithReq(r).IsAllowed() != nil {
+		http.Error(w, "permission denied", http.StatusForbidden)
+		return
+	}
+
+	a := reflectutils.MustGet(obj, fieldName).(Attachment)
+	if len(a.Key) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := b.p.attachmentStorage.Get(a.Key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", a.ContentType)
+	// the browsers don't sniff the uploaded files as other types like html
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q; filename*=UTF-8''%s", a.FileName, url.PathEscape(a.FileName)))
+	_, _ = io.Copy(w, body)
+}
+
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
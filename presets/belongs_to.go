@@ -0,0 +1,241 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/presets/actions"
+	"github.com/goplaid/x/vuetifyx"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// relatedOptionsPerPage is the number of the records searched for the options of the autocompletes of the related records
+const relatedOptionsPerPage = 20
+
+type autocompleteOption struct {
+	Text  string      `json:"text"`
+	Value interface{} `json:"value"`
+}
+
+// belongsTo finds the model registered in the builder that the field like CompanyID refers to, like Company
+func (b *Builder) belongsTo(fieldName string) (r *ModelBuilder) {
+	name := strings.TrimSuffix(fieldName, "ID")
+	if len(name) == 0 || len(name) == len(fieldName) {
+		return
+	}
+	for _, m := range b.models {
+		if m.modelType.Elem().Name() == name {
+			return m
+		}
+	}
+	return
+}
+
+func (b *ModelInfo) belongsTo(fieldName string) (r *ModelBuilder) {
+	if b == nil {
+		return
+	}
+	return b.p.belongsTo(fieldName)
+}
+
+// belongsToOr renders the field as the relationship if the model it refers to is registered,
+// the models are looked up when rendering, that they can be registered in any order
+func belongsToOr(mode FieldMode, compFunc FieldComponentFunc) FieldComponentFunc {
+	return func(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+		rm := field.ModelInfo.belongsTo(field.Name)
+		if rm == nil {
+			return compFunc(obj, field, ctx)
+		}
+		switch mode {
+		case LIST:
+			return h.Td(belongsToLink(rm, obj, field, ctx))
+		case DETAIL:
+			return h.Div(
+				h.Div(h.Text(field.Label)).Class("text-caption grey--text"),
+				belongsToLink(rm, obj, field, ctx),
+			).Class("mb-4")
+		}
+		return belongsToAutocomplete(rm, obj, field, ctx)
+	}
+}
+
+type belongsToRecordsKey struct{}
+
+// preloadBelongsTo fetches the records that the belongs-to fields of the page refer to with one search for each field,
+// and keeps them in the context of the request for rendering the cells
+func (b *ListingBuilder) preloadBelongsTo(objs interface{}, fields []*FieldBuilder, ctx *web.EventContext) (err error) {
+	records := map[string]map[string]interface{}{}
+	rv := reflect.ValueOf(objs)
+	for _, f := range fields {
+		rm := b.mb.p.belongsTo(f.name)
+		if rm == nil || rm.Info().Verifier().Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+			continue
+		}
+
+		var ids []interface{}
+		seen := map[interface{}]bool{}
+		for i := 0; i < rv.Len(); i++ {
+			val := reflectutils.MustGet(rv.Index(i).Interface(), f.name)
+			if !reflect.ValueOf(val).IsZero() && !seen[val] {
+				seen[val] = true
+				ids = append(ids, val)
+			}
+		}
+
+		records[f.name], err = rm.searchByIDs(ids, ctx)
+		if err != nil {
+			return
+		}
+	}
+
+	ctx.R = ctx.R.WithContext(context.WithValue(ctx.R.Context(), belongsToRecordsKey{}, records))
+	return
+}
+
+// searchByIDs searches the records of ids with the searcher of the listing in one query, r is the records by ids
+func (b *ModelBuilder) searchByIDs(ids []interface{}, ctx *web.EventContext) (r map[string]interface{}, err error) {
+	r = map[string]interface{}{}
+	if len(ids) == 0 {
+		return
+	}
+
+	objs, _, err := b.listing.searcher(b.newModelArray(), &SearchParams{
+		SQLConditions: []*SQLCondition{{
+			Query: fmt.Sprintf("%s IN (?)", sortKey(b.primaryField)),
+			Args:  []interface{}{ids},
+		}},
+	}, ctx)
+	if err != nil {
+		return
+	}
+	rv := reflect.ValueOf(objs)
+	for i := 0; i < rv.Len(); i++ {
+		obj := rv.Index(i).Interface()
+		// keyed by the primary field like the foreign keys and the option values, not the slugs of the urls
+		r[fmt.Sprint(reflectutils.MustGet(obj, b.primaryField))] = obj
+	}
+	return
+}
+
+// belongsToFetch fetches the record that the field refers to, or takes it from the preloaded records of the listing,
+// r is nil if the field is not set, or the user can't list the model or get the record
+func belongsToFetch(rm *ModelBuilder, obj interface{}, field *FieldContext, ctx *web.EventContext) (r interface{}, id string, err error) {
+	val := reflectutils.MustGet(obj, field.Name)
+	if reflect.ValueOf(val).IsZero() {
+		return
+	}
+	id = fmt.Sprint(val)
+	if rm.Info().Verifier().Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+		return
+	}
+
+	if records, ok := ctx.R.Context().Value(belongsToRecordsKey{}).(map[string]map[string]interface{}); ok && records[field.Name] != nil {
+		r = records[field.Name][id]
+	} else {
+		r, err = rm.editing.fetcher(rm.newModel(), id, ctx)
+		if err != nil {
+			return
+		}
+	}
+
+	if r != nil && rm.Info().Verifier().Do(PermGet).ObjectOn(r).WithReq(ctx.R).IsAllowed() != nil {
+		r = nil
+	}
+	return
+}
+
+// belongsToLink links to the record that the field refers to, it's the id if the record can't be shown
+func belongsToLink(rm *ModelBuilder, obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	robj, id, err := belongsToFetch(rm, obj, field, ctx)
+	if err != nil || robj == nil {
+		return h.Text(id)
+	}
+
+	return relatedLink(rm, robj, rm.objectID(robj), ctx)
+}
+
+// relatedLink shows the PageTitle of the related record that links to the detail page, or the edit drawer of the model
+func relatedLink(rm *ModelBuilder, robj interface{}, id string, ctx *web.EventContext) h.HTMLComponent {
+	mi := rm.Info()
+	title := getPageTitle(robj, id)
+	if mi.HasDetailing() {
+		return h.A().Text(title).Attr("@click", web.Plaid().PushStateURL(mi.DetailingHref(id)).Go())
+	}
+	if mi.Verifier().Do(PermUpdate).ObjectOn(robj).WithReq(ctx.R).IsAllowed() != nil {
+		return h.Text(title)
+	}
+	return h.A().Text(title).Attr("@click", web.Plaid().EventFunc(actions.DrawerEdit, id).URL(mi.ListingHref()).Go())
+}
+
+func belongsToAutocomplete(rm *ModelBuilder, obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	var selected []*autocompleteOption
+	robj, id, err := belongsToFetch(rm, obj, field, ctx)
+	if robj != nil && err == nil {
+		selected = append(selected, rm.relatedOption(robj))
+	} else if len(id) > 0 {
+		selected = append(selected, &autocompleteOption{Text: id, Value: reflectutils.MustGet(obj, field.Name)})
+	}
+
+	return vuetifyx.VXAutocomplete().
+		FieldName(field.Name).
+		Label(field.Label).
+		ItemText("text").
+		ItemValue("value").
+		Multiple(false).
+		SelectedItems(selected).
+		ItemsEventFunc(actions.BelongsToOptions, field.Name).
+		Value(reflectutils.MustGet(obj, field.Name)).
+		ErrorMessages(field.Errors...)
+}
+
+// belongsToOptions searches the options of the autocomplete with the searcher of the model the field refers to
+func (b *ModelBuilder) belongsToOptions(ctx *web.EventContext) (r web.EventResponse, err error) {
+	rm := b.p.belongsTo(ctx.Event.Params[0])
+	if rm == nil {
+		err = fmt.Errorf("%s is not a relationship of %s", ctx.Event.Params[0], b.label)
+		return
+	}
+	return rm.relatedOptions(ctx)
+}
+
+// relatedOptions searches the records matching the keyword of the autocomplete for the fields of the other models that refer to it
+func (b *ModelBuilder) relatedOptions(ctx *web.EventContext) (r web.EventResponse, err error) {
+	options := []*autocompleteOption{}
+	// the user can't choose from the records that the user can't list
+	if b.Info().Verifier().Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+		r.Data = options
+		return
+	}
+
+	lb := b.listing
+	objs, _, err := lb.searcher(b.newModelArray(), &SearchParams{
+		KeywordColumns: lb.searchColumns,
+		Keyword:        strings.TrimSpace(ctx.Event.Value),
+		FullTextSearch: lb.fullTextSearch,
+		RankByKeyword:  lb.fullTextSearch != nil,
+		PerPage:        relatedOptionsPerPage,
+		Page:           1,
+		OrderBy:        lb.defaultOrderBy(),
+	}, ctx)
+	if err != nil {
+		return
+	}
+
+	rv := reflect.ValueOf(objs)
+	for i := 0; i < rv.Len(); i++ {
+		options = append(options, b.relatedOption(rv.Index(i).Interface()))
+	}
+	r.Data = options
+	return
+}
+
+func (b *ModelBuilder) relatedOption(obj interface{}) *autocompleteOption {
+	return &autocompleteOption{
+		Text:  getPageTitle(obj, b.objectID(obj)),
+		Value: reflectutils.MustGet(obj, b.primaryField),
+	}
+}
@@ -0,0 +1,382 @@
+package presets
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+const calendarPortalName = "calendar"
+const calendarTypeParamName = "calendarType"
+const calendarDateParamName = "calendarDate"
+const calendarEventFieldName = "calendarEvent"
+const calendarFromFieldName = "calendarFrom"
+const calendarToFieldName = "calendarTo"
+
+const calendarDateFormat = "2006-01-02"
+const calendarTimeFormat = "2006-01-02 15:04"
+
+type CalendarEventTitleFunc func(obj interface{}, ctx *web.EventContext) string
+
+type CalendarBuilder struct {
+	lb             *ListingBuilder
+	startField     string
+	endField       string
+	eventTitleFunc CalendarEventTitleFunc
+	isDefault      bool
+}
+
+// Calendar adds a calendar layout to the listing that shows the records as events at the time of the field,
+// that is a time.Time or *time.Time, dragging an event moves the times with the setters, validator and saver of the editing form
+func (b *ListingBuilder) Calendar(startField string) (r *CalendarBuilder) {
+	if b.calendar == nil || b.calendar.startField != startField {
+		b.calendar = &CalendarBuilder{lb: b, startField: startField}
+	}
+	return b.calendar
+}
+
+// EndField makes the events last to the time of the field, events without it are an hour long
+func (b *CalendarBuilder) EndField(v string) (r *CalendarBuilder) {
+	b.endField = v
+	return b
+}
+
+// EventTitleFunc returns the names of the events, defaults to the page title of the record
+func (b *CalendarBuilder) EventTitleFunc(v CalendarEventTitleFunc) (r *CalendarBuilder) {
+	b.eventTitleFunc = v
+	return b
+}
+
+// Default shows the calendar instead of the table when the layout is not chosen in url
+func (b *CalendarBuilder) Default(v bool) (r *CalendarBuilder) {
+	b.isDefault = v
+	return b
+}
+
+type calendarEvent struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Timed bool   `json:"timed"`
+	Href  string `json:"href,omitempty"`
+}
+
+func calendarType(ctx *web.EventContext) string {
+	switch t := ctx.R.URL.Query().Get(calendarTypeParamName); t {
+	case "day", "week":
+		return t
+	}
+	return "month"
+}
+
+// calendarDate is the day in url or today, in the time zone of the user
+func calendarDate(loc *time.Location, ctx *web.EventContext) time.Time {
+	d, err := time.ParseInLocation(calendarDateFormat, ctx.R.URL.Query().Get(calendarDateParamName), loc)
+	if err != nil {
+		now := time.Now().In(loc)
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	}
+	return d
+}
+
+// calendarRange is the days that the calendar shows, whole weeks for month
+func calendarRange(typ string, date time.Time) (start time.Time, end time.Time) {
+	switch typ {
+	case "day":
+		return date, date.AddDate(0, 0, 1)
+	case "week":
+		start = date.AddDate(0, 0, -int(date.Weekday()))
+		return start, start.AddDate(0, 0, 7)
+	}
+
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	last := first.AddDate(0, 1, -1)
+	start = first.AddDate(0, 0, -int(first.Weekday()))
+	end = last.AddDate(0, 0, 7-int(last.Weekday()))
+	return
+}
+
+// calendarStep is the date of the previous or next month, week or day
+func calendarStep(typ string, date time.Time, n int) time.Time {
+	switch typ {
+	case "day":
+		return date.AddDate(0, 0, n)
+	case "week":
+		return date.AddDate(0, 0, 7*n)
+	}
+	return time.Date(date.Year(), date.Month()+time.Month(n), 1, 0, 0, 0, 0, date.Location())
+}
+
+func calendarTitle(typ string, date time.Time) string {
+	switch typ {
+	case "day":
+		return date.Format("Jan 2, 2006")
+	case "week":
+		start, end := calendarRange(typ, date)
+		return fmt.Sprintf("%s - %s", start.Format("Jan 2"), end.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+	}
+	return date.Format("January 2006")
+}
+
+func timeValue(obj interface{}, fieldName string) (t time.Time, ok bool) {
+	switch v := reflectutils.MustGet(obj, fieldName).(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v != nil {
+			return *v, !v.IsZero()
+		}
+	}
+	return
+}
+
+func setTimeValue(obj interface{}, fieldName string, t time.Time) (err error) {
+	if _, ok := reflectutils.MustGet(obj, fieldName).(*time.Time); ok {
+		return reflectutils.Set(obj, fieldName, &t)
+	}
+	return reflectutils.Set(obj, fieldName, t)
+}
+
+// calendar searches the records whose times are in the days the calendar shows, with the listing search
+func (b *CalendarBuilder) calendar(msgr *Messages, alert h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	typ := calendarType(ctx)
+	loc := b.lb.mb.p.timeLocation(ctx.R)
+	date := calendarDate(loc, ctx)
+	start, end := calendarRange(typ, date)
+
+	searchParams, _ := b.lb.searchParamsFromQuery(ctx)
+	searchParams.PerPage = 0
+	searchParams.Page = 0
+	searchParams.OrderBy = fmt.Sprintf("%s ASC", sortKey(b.startField))
+
+	startColumn := sortKey(b.startField)
+	cond := &SQLCondition{
+		Query: fmt.Sprintf("%s >= ? AND %s < ?", startColumn, startColumn),
+		Args:  []interface{}{start, end},
+	}
+	if len(b.endField) > 0 {
+		endColumn := sortKey(b.endField)
+		cond = &SQLCondition{
+			Query: fmt.Sprintf("%s < ? AND (%s >= ? OR (%s IS NULL AND %s >= ?))", startColumn, endColumn, endColumn, startColumn),
+			Args:  []interface{}{end, start, start},
+		}
+	}
+	searchParams.SQLConditions = append(searchParams.SQLConditions, cond)
+
+	objs, _, err := b.lb.searcher(b.lb.mb.newModelArray(), searchParams, ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	events := []*calendarEvent{}
+	rv := reflect.ValueOf(objs)
+	for i := 0; i < rv.Len(); i++ {
+		obj := rv.Index(i).Interface()
+		if e := b.event(obj, loc, ctx); e != nil {
+			events = append(events, e)
+		}
+	}
+
+	navBtn := func(icon string, d time.Time) h.HTMLComponent {
+		return VBtn("").Icon(true).Children(VIcon(icon)).
+			Attr("@click", web.Plaid().
+				PushStateQuery(url.Values{calendarDateParamName: []string{d.Format(calendarDateFormat)}}).
+				MergeQuery(true).
+				Go())
+	}
+	typeBtn := func(t string, label string) h.HTMLComponent {
+		btn := VBtn(label).Text(true).Small(true).
+			Attr("@click", web.Plaid().
+				PushStateQuery(url.Values{calendarTypeParamName: []string{t}}).
+				MergeQuery(true).
+				Go())
+		if t == typ {
+			btn.Color("primary")
+		}
+		return btn
+	}
+
+	var click string
+	if b.lb.mb.hasDetailing {
+		click = web.Plaid().Raw("pushStateURL($event.event.href)").Go()
+	} else {
+		click = web.Plaid().Raw(fmt.Sprintf("eventFunc(%s, $event.event.id)", h.JSONString(actions.DrawerEdit))).Go()
+	}
+
+	// dragging picks the event and the time it's picked at, and dropping reschedules the event by the difference
+	drop := func(to string) string {
+		return fmt.Sprintf("if (vars.calendarDrag && vars.calendarDrag.from && vars.calendarDrag.from != %s) { %s }; vars.calendarDrag = null",
+			to,
+			web.Plaid().
+				EventFunc(actions.RescheduleCalendarEvent).
+				FieldValue(calendarEventFieldName, web.Var("vars.calendarDrag.id")).
+				FieldValue(calendarFromFieldName, web.Var("vars.calendarDrag.from")).
+				FieldValue(calendarToFieldName, web.Var(to)).
+				Go(),
+		)
+	}
+	pick := func(from string) string {
+		return fmt.Sprintf("if (vars.calendarDrag && !vars.calendarDrag.from) { vars.calendarDrag.from = %s }", from)
+	}
+	const timeOfSlot = "$event.date + ' ' + $event.time"
+	const dateOfSlot = "$event.date"
+
+	return h.Div(
+		alert,
+		VToolbar(
+			VBtn(msgr.CalendarToday).Depressed(true).Class("mr-2").
+				Attr("@click", web.Plaid().
+					PushStateQuery(url.Values{calendarDateParamName: []string{}}).
+					MergeQuery(true).
+					Go()),
+			navBtn("chevron_left", calendarStep(typ, date, -1)),
+			navBtn("chevron_right", calendarStep(typ, date, 1)),
+			VToolbarTitle(calendarTitle(typ, date)).Class("ml-2"),
+			VSpacer(),
+			typeBtn("month", msgr.CalendarMonth),
+			typeBtn("week", msgr.CalendarWeek),
+			typeBtn("day", msgr.CalendarDay),
+		).Flat(true).Dense(true),
+		VSheet(
+			VCalendar().
+				Type(typ).
+				Events(events).
+				EventColor("primary").
+				EventTimed("timed").
+				Attr("value", date.Format(calendarDateFormat)).
+				Attr("@click:event", click).
+				Attr("@mousedown:event", "vars.calendarDrag = {id: $event.event.id, from: ''}").
+				Attr("@mousedown:time", pick(timeOfSlot)).
+				Attr("@mousedown:day", pick(dateOfSlot)).
+				Attr("@mouseup:time", drop(timeOfSlot)).
+				Attr("@mouseup:day", drop(dateOfSlot)).
+				Attr("@mouseleave.native", "vars.calendarDrag = null"),
+		).Height(600),
+	).Attr(web.InitContextVars, `{calendarDrag: null}`)
+}
+
+func (b *CalendarBuilder) event(obj interface{}, loc *time.Location, ctx *web.EventContext) (r *calendarEvent) {
+	start, ok := timeValue(obj, b.startField)
+	if !ok {
+		return
+	}
+	start = start.In(loc)
+
+	end := start.Add(time.Hour)
+	if len(b.endField) > 0 {
+		if t, ok := timeValue(obj, b.endField); ok && t.After(start) {
+			end = t.In(loc)
+		}
+	}
+
+	id := b.lb.mb.objectID(obj)
+	r = &calendarEvent{
+		ID:    id,
+		Start: start.Format(calendarTimeFormat),
+		End:   end.Format(calendarTimeFormat),
+		Timed: true,
+	}
+	if b.eventTitleFunc != nil {
+		r.Name = b.eventTitleFunc(obj, ctx)
+	} else {
+		r.Name = getPageTitle(obj, id)
+	}
+	if b.lb.mb.hasDetailing {
+		r.Href = b.lb.mb.Info().DetailingHref(id)
+	}
+	return
+}
+
+// calendarMoveDuration is how far the event is dragged, in days when dropped in the month view,
+// otherwise rounded to 15 minutes so that a click doesn't move it
+func calendarMoveDuration(from string, to string, loc *time.Location) (d time.Duration, err error) {
+	layout := calendarTimeFormat
+	if len(from) == len(calendarDateFormat) {
+		layout = calendarDateFormat
+	}
+
+	f, err := time.ParseInLocation(layout, from, loc)
+	if err != nil {
+		return
+	}
+	t, err := time.ParseInLocation(layout, to, loc)
+	if err != nil {
+		return
+	}
+
+	if layout == calendarDateFormat {
+		return time.Duration(math.Round(t.Sub(f).Hours()/24)) * 24 * time.Hour, nil
+	}
+	return t.Sub(f).Round(15 * time.Minute), nil
+}
+
+// rescheduleCalendarEvent moves the start and end time of the record by the dragged duration,
+// and renders the calendar again with the error if it fails
+func (b *CalendarBuilder) rescheduleCalendarEvent(ctx *web.EventContext) (r web.EventResponse, err error) {
+	msgr := MustGetMessages(ctx.R)
+	id := ctx.R.FormValue(calendarEventFieldName)
+	d, err := calendarMoveDuration(ctx.R.FormValue(calendarFromFieldName), ctx.R.FormValue(calendarToFieldName), b.lb.mb.p.timeLocation(ctx.R))
+	if err != nil || len(id) == 0 {
+		err = fmt.Errorf("can't reschedule calendar event %s: %v", id, err)
+		return
+	}
+
+	err1 := b.reschedule(id, d, ctx)
+
+	var alert h.HTMLComponent
+	if err1 != nil {
+		alert = VAlert(h.Text(err1.Error())).
+			Dense(true).
+			Type("error").
+			Class("mb-3")
+	}
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: calendarPortalName,
+		Body: b.calendar(msgr, alert, ctx),
+	})
+	return
+}
+
+// reschedule submits the moved times to the editing pipeline of the start and end fields,
+// as the form values of the time setter and of the default form decoding
+func (b *CalendarBuilder) reschedule(id string, d time.Duration, ctx *web.EventContext) (err error) {
+	if d == 0 {
+		return
+	}
+
+	fields := []string{b.startField}
+	if len(b.endField) > 0 {
+		fields = append(fields, b.endField)
+	}
+
+	eb := b.lb.inlineEditingBuilder(fields...)
+	obj, err := eb.fetcher(b.lb.mb.newModel(), id, ctx)
+	if err != nil {
+		return
+	}
+
+	loc := b.lb.mb.p.timeLocation(ctx.R)
+	values := url.Values{}
+	for _, f := range fields {
+		t, ok := timeValue(obj, f)
+		if !ok {
+			continue
+		}
+		t = t.Add(d)
+		values.Set(f, t.Format(time.RFC3339Nano))
+		values.Set(timeFieldName(f), t.In(loc).Format(fieldTimeKind(&FieldContext{Context: eb.Field(f).context}).layout()))
+	}
+
+	_, err = b.lb.updateFields(eb, id, formContext(values, ctx))
+	return
+}
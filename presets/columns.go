@@ -0,0 +1,235 @@
+package presets
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/i18n"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+const columnsParamName = "columns"
+
+// ListingColumnsStore stores the listing columns that users choose, in the order of display
+type ListingColumnsStore interface {
+	GetColumns(userID string, model string) (r []string, err error)
+	SaveColumns(userID string, model string, columns []string) (err error)
+}
+
+// ListingColumnsStore persists the columns chosen by the users identified by UserIDFunc,
+// without it the columns are only kept in url
+func (b *Builder) ListingColumnsStore(v ListingColumnsStore) (r *Builder) {
+	b.listingColumnsStore = v
+	return b
+}
+
+type MemoryListingColumnsStore struct {
+	mu      sync.RWMutex
+	columns map[string][]string
+}
+
+func NewMemoryListingColumnsStore() (r *MemoryListingColumnsStore) {
+	return &MemoryListingColumnsStore{columns: map[string][]string{}}
+}
+
+func (s *MemoryListingColumnsStore) GetColumns(userID string, model string) (r []string, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r = append(r, s.columns[userID+"/"+model]...)
+	return
+}
+
+func (s *MemoryListingColumnsStore) SaveColumns(userID string, model string, columns []string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.columns[userID+"/"+model] = append([]string{}, columns...)
+	return
+}
+
+// ColumnChooser adds a menu to the listing toolbar for users to show, hide and reorder
+// the listing fields and the fields of ChoosableColumns
+func (b *ListingBuilder) ColumnChooser(v bool) (r *ListingBuilder) {
+	b.columnChooser = v
+	return b
+}
+
+// ChoosableColumns are the fields of the model other than the listing fields, that users can choose to show
+// and export, so that the fields like password hashes are never chosen with columns in url
+func (b *ListingBuilder) ChoosableColumns(vs ...string) (r *ListingBuilder) {
+	b.choosableColumns = vs
+	return b
+}
+
+func (b *Builder) userID(r *http.Request) string {
+	if b.userIDFunc == nil {
+		return ""
+	}
+	return b.userIDFunc(r)
+}
+
+// getField finds the field in the listing fields, or the fields inspected from the model of ChoosableColumns
+func (b *ListingBuilder) getField(name string) (r *FieldBuilder) {
+	if r = b.GetField(name); r != nil {
+		return
+	}
+	for _, c := range b.choosableColumns {
+		if c == name {
+			return b.mb.p.listFieldDefaults.InspectFields(b.mb.model).GetField(name)
+		}
+	}
+	return
+}
+
+// chosenColumns returns the columns in url, or the ones that the user saved
+func (b *ListingBuilder) chosenColumns(r *http.Request) (columns []string) {
+	if !b.columnChooser {
+		return
+	}
+
+	if v := r.URL.Query().Get(columnsParamName); len(v) > 0 {
+		return strings.Split(v, ",")
+	}
+
+	userID := b.mb.p.userID(r)
+	if b.mb.p.listingColumnsStore == nil || len(userID) == 0 {
+		return
+	}
+
+	columns, err := b.mb.p.listingColumnsStore.GetColumns(userID, b.mb.uriName)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// visibleFields are the fields to show in the listing and export, in the order users choose
+func (b *ListingBuilder) visibleFields(r *http.Request) (fields []*FieldBuilder) {
+	for _, name := range b.chosenColumns(r) {
+		if f := b.getField(name); f != nil {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return b.fields
+	}
+	return
+}
+
+func fieldNames(fields []*FieldBuilder) (r []string) {
+	for _, f := range fields {
+		r = append(r, f.name)
+	}
+	return
+}
+
+func (b *ListingBuilder) columnChooserMenu(msgr *Messages, ctx *web.EventContext) h.HTMLComponent {
+	if !b.columnChooser {
+		return nil
+	}
+
+	visible := fieldNames(b.visibleFields(ctx.R))
+	isVisible := map[string]bool{}
+	for _, n := range visible {
+		isVisible[n] = true
+	}
+
+	var hidden []*FieldBuilder
+	for _, name := range append(fieldNames(b.fields), b.choosableColumns...) {
+		f := b.getField(name)
+		if f != nil && !isVisible[name] {
+			isVisible[name] = true
+			hidden = append(hidden, f)
+		}
+	}
+
+	updateColumns := func(columns []string) string {
+		return web.Plaid().
+			EventFunc(actions.UpdateListingColumns, strings.Join(columns, ",")).
+			Go()
+	}
+
+	var items []h.HTMLComponent
+	for i, name := range visible {
+		f := b.getField(name)
+
+		var without []string
+		without = append(without, visible[:i]...)
+		without = append(without, visible[i+1:]...)
+
+		var upBtn, downBtn h.HTMLComponent
+		if i > 0 {
+			moved := append([]string{}, visible...)
+			moved[i-1], moved[i] = moved[i], moved[i-1]
+			upBtn = VBtn("").Icon(true).Small(true).Children(VIcon("arrow_upward").Small(true)).
+				Attr("@click.stop", updateColumns(moved))
+		}
+		if i < len(visible)-1 {
+			moved := append([]string{}, visible...)
+			moved[i+1], moved[i] = moved[i], moved[i+1]
+			downBtn = VBtn("").Icon(true).Small(true).Children(VIcon("arrow_downward").Small(true)).
+				Attr("@click.stop", updateColumns(moved))
+		}
+
+		items = append(items, VListItem(
+			VListItemAction(VIcon("check_box").Color("primary")),
+			VListItemContent(VListItemTitle(h.Text(b.columnLabel(f, ctx)))),
+			VListItemAction(h.Div(upBtn, downBtn).Class("d-flex")),
+		).Disabled(len(visible) == 1).Attr("@click", updateColumns(without)))
+	}
+
+	for _, f := range hidden {
+		items = append(items, VListItem(
+			VListItemAction(VIcon("check_box_outline_blank")),
+			VListItemContent(VListItemTitle(h.Text(b.columnLabel(f, ctx)))),
+		).Attr("@click", updateColumns(append(append([]string{}, visible...), f.name))))
+	}
+
+	items = append(items,
+		VDivider(),
+		VListItem(
+			VListItemTitle(h.Text(msgr.ResetColumns)),
+		).Attr("@click", updateColumns(nil)),
+	)
+
+	return VMenu(
+		web.Slot(
+			VBtn("").Icon(true).Class("ml-2").Children(
+				VIcon("view_column"),
+			).Attr("v-on", "on").Attr("title", msgr.Columns),
+		).Name("activator").Scope("{ on }"),
+		VList(items...).Dense(true),
+	).OffsetY(true).CloseOnContentClick(false)
+}
+
+func (b *ListingBuilder) columnLabel(f *FieldBuilder, ctx *web.EventContext) string {
+	return i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, b.mb.getLabel(f.NameLabel))
+}
+
+// updateListingColumns saves the columns for the user and shows them in url,
+// so that saved views keep the columns, empty columns resets to the default listing fields
+func (b *ListingBuilder) updateListingColumns(ctx *web.EventContext) (r web.EventResponse, err error) {
+	var columns []string
+	if v := ctx.Event.Params[0]; len(v) > 0 {
+		for _, name := range strings.Split(v, ",") {
+			if b.getField(name) != nil {
+				columns = append(columns, name)
+			}
+		}
+	}
+
+	userID := b.mb.p.userID(ctx.R)
+	if b.mb.p.listingColumnsStore != nil && len(userID) > 0 {
+		err = b.mb.p.listingColumnsStore.SaveColumns(userID, b.mb.uriName, columns)
+		if err != nil {
+			return
+		}
+	}
+
+	r.PushState = web.PushState(url.Values{columnsParamName: []string{strings.Join(columns, ",")}}).MergeQuery(true)
+	return
+}
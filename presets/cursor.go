@@ -0,0 +1,270 @@
+package presets
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/goplaid/web"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+const cursorParamName = "cursor"
+
+// SearchCursor is set to SearchParams in cursor pagination mode, searchers should skip the total count
+// and the page offset, and use SQLCondition and OrderBy of the cursor instead of SearchParams.OrderBy
+type SearchCursor struct {
+	Columns []*CursorColumn
+	// values of the columns of the record that the page starts after, empty for the first page
+	Values []interface{}
+	// search the records before Values, that are ordered in reverse and restored by Records
+	Backward bool
+}
+
+type CursorColumn struct {
+	FieldName string
+	Column    string
+	Desc      bool
+	// Nullable columns are ordered with NULLs after the other values, and the first in descending order
+	Nullable bool
+}
+
+// SQLCondition returns the keyset condition of records after Values, or before Values if Backward,
+// like (a > ?) OR (a = ? AND id > ?), nil for the first page
+func (c *SearchCursor) SQLCondition() *SQLCondition {
+	if len(c.Values) == 0 {
+		return nil
+	}
+
+	var ors []string
+	var args []interface{}
+	for i, col := range c.Columns {
+		var ands []string
+		for j := 0; j < i; j++ {
+			if c.Columns[j].Nullable && isNullValue(c.Values[j]) {
+				ands = append(ands, fmt.Sprintf("%s IS NULL", c.Columns[j].Column))
+				continue
+			}
+			ands = append(ands, fmt.Sprintf("%s = ?", c.Columns[j].Column))
+			args = append(args, c.Values[j])
+		}
+
+		desc := col.Desc != c.Backward
+		switch {
+		case !col.Nullable:
+			op := ">"
+			if desc {
+				op = "<"
+			}
+			ands = append(ands, fmt.Sprintf("%s %s ?", col.Column, op))
+			args = append(args, c.Values[i])
+		case isNullValue(c.Values[i]):
+			if !desc {
+				// nothing is after NULLs in ascending order
+				continue
+			}
+			ands = append(ands, fmt.Sprintf("%s IS NOT NULL", col.Column))
+		case desc:
+			ands = append(ands, fmt.Sprintf("%s < ?", col.Column))
+			args = append(args, c.Values[i])
+		default:
+			ands = append(ands, fmt.Sprintf("(%s > ? OR %s IS NULL)", col.Column, col.Column))
+			args = append(args, c.Values[i])
+		}
+		ors = append(ors, fmt.Sprintf("(%s)", strings.Join(ands, " AND ")))
+	}
+
+	return &SQLCondition{
+		Query: strings.Join(ors, " OR "),
+		Args:  args,
+	}
+}
+
+// OrderBy sorts NULLs of nullable columns with IS NULL, that databases order them differently by default
+func (c *SearchCursor) OrderBy() string {
+	var segs []string
+	for _, col := range c.Columns {
+		dir := "ASC"
+		if col.Desc != c.Backward {
+			dir = "DESC"
+		}
+		if col.Nullable {
+			segs = append(segs, fmt.Sprintf("%s IS NULL %s", col.Column, dir))
+		}
+		segs = append(segs, fmt.Sprintf("%s %s", col.Column, dir))
+	}
+	return strings.Join(segs, ", ")
+}
+
+// isNullValue tells if v is saved as NULL, like nil pointers and invalid sql.NullString
+func isNullValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return true
+		}
+		dv, err := valuer.Value()
+		return err == nil && dv == nil
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// isNullableType tells if the fields of t can be NULL, which are pointers and the types like sql.NullString
+func isNullableType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return true
+	}
+	_, ok := t.FieldByName("Valid")
+	return t.Kind() == reflect.Struct && ok && t.Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem())
+}
+
+// Records restores the listing order of the searched records slice, that is reversed if Backward
+func (c *SearchCursor) Records(objs interface{}) interface{} {
+	if !c.Backward {
+		return objs
+	}
+	rv := reflect.ValueOf(objs)
+	for i, j := 0, rv.Len()-1; i < j; i, j = i+1, j-1 {
+		vi, vj := rv.Index(i).Interface(), rv.Index(j).Interface()
+		rv.Index(i).Set(reflect.ValueOf(vj))
+		rv.Index(j).Set(reflect.ValueOf(vi))
+	}
+	return objs
+}
+
+// CursorPagination replaces the numbered pagination with previous and next buttons,
+// that searches with a SearchCursor of the order columns instead of counting and offsetting,
+// records are ordered by the sortable field in url and the primary field, OrderBy is not used
+func (b *ListingBuilder) CursorPagination(v bool) (r *ListingBuilder) {
+	b.cursorPagination = v
+	return b
+}
+
+type cursorToken struct {
+	OrderBy  string            `json:"o,omitempty"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+func (b *ListingBuilder) cursorColumns(orderBy string) (r []*CursorColumn) {
+	if f, desc := b.sortableFieldFromQuery(orderBy); len(f) > 0 {
+		var nullable bool
+		if sf, ok := b.mb.modelType.Elem().FieldByName(f); ok {
+			nullable = isNullableType(sf.Type)
+		}
+		r = append(r, &CursorColumn{FieldName: f, Column: sortKey(f), Desc: desc, Nullable: nullable})
+	}
+	return append(r, &CursorColumn{
+		FieldName: b.mb.primaryField,
+		Column:    sortKey(b.mb.primaryField),
+		Desc:      true,
+	})
+}
+
+// cursorFromQuery decodes the cursor in url, cursors of another order are ignored and start from the first page
+func (b *ListingBuilder) cursorFromQuery(ctx *web.EventContext) (r *SearchCursor) {
+	orderBy := ctx.R.URL.Query().Get(orderByParamName)
+	r = &SearchCursor{Columns: b.cursorColumns(orderBy)}
+
+	v := ctx.R.URL.Query().Get(cursorParamName)
+	if len(v) == 0 {
+		return
+	}
+
+	bs, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return
+	}
+	var token cursorToken
+	if json.Unmarshal(bs, &token) != nil || token.OrderBy != orderBy || len(token.Values) != len(r.Columns) {
+		return
+	}
+
+	var values []interface{}
+	for i, col := range r.Columns {
+		sf, ok := b.mb.modelType.Elem().FieldByName(col.FieldName)
+		if !ok {
+			return
+		}
+		val := reflect.New(sf.Type)
+		if json.Unmarshal(token.Values[i], val.Interface()) != nil {
+			return
+		}
+		values = append(values, val.Elem().Interface())
+	}
+
+	r.Values = values
+	r.Backward = token.Backward
+	return
+}
+
+func (b *ListingBuilder) cursorTokenOf(obj interface{}, backward bool, ctx *web.EventContext) string {
+	orderBy := ctx.R.URL.Query().Get(orderByParamName)
+	token := cursorToken{OrderBy: orderBy, Backward: backward}
+	for _, col := range b.cursorColumns(orderBy) {
+		bs, err := json.Marshal(reflectutils.MustGet(obj, col.FieldName))
+		if err != nil {
+			panic(err)
+		}
+		token.Values = append(token.Values, bs)
+	}
+
+	bs, err := json.Marshal(token)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bs)
+}
+
+// cursorPage trims the one more record that is searched to know if there is a page after it,
+// and returns the records of the page with whether there are previous and next pages
+func cursorPage(objs interface{}, cursor *SearchCursor, perPage int64) (r interface{}, hasPrev bool, hasNext bool) {
+	rv := reflect.ValueOf(objs)
+	more := int64(rv.Len()) > perPage
+	if more {
+		if cursor.Backward {
+			rv = rv.Slice(1, rv.Len())
+		} else {
+			rv = rv.Slice(0, int(perPage))
+		}
+	}
+
+	if cursor.Backward {
+		return rv.Interface(), more, true
+	}
+	return rv.Interface(), len(cursor.Values) > 0, more
+}
+
+func (b *ListingBuilder) cursorPaginator(objs interface{}, hasPrev bool, hasNext bool, ctx *web.EventContext) h.HTMLComponent {
+	if !hasPrev && !hasNext {
+		return nil
+	}
+
+	rv := reflect.ValueOf(objs)
+	var prevBtn = VBtn("").Icon(true).Disabled(!hasPrev).Children(VIcon("chevron_left"))
+	var nextBtn = VBtn("").Icon(true).Disabled(!hasNext).Children(VIcon("chevron_right"))
+	if hasPrev && rv.Len() > 0 {
+		prevBtn.Attr("@click", web.Plaid().
+			PushStateQuery(url.Values{cursorParamName: []string{b.cursorTokenOf(rv.Index(0).Interface(), true, ctx)}}).
+			MergeQuery(true).
+			Go())
+	}
+	if hasNext && rv.Len() > 0 {
+		nextBtn.Attr("@click", web.Plaid().
+			PushStateQuery(url.Values{cursorParamName: []string{b.cursorTokenOf(rv.Index(rv.Len()-1).Interface(), false, ctx)}}).
+			MergeQuery(true).
+			Go())
+	}
+
+	return h.Div(prevBtn, nextBtn).Class("d-flex justify-center mt-2")
+}
@@ -50,6 +50,16 @@ func (b *DetailingBuilder) Fetcher(v FetchFunc) (r *DetailingBuilder) {
 	return b
 }
 
+// fetchWithAssociations is the default Fetcher, it fetches the object with the many-to-many fields of the page
+// if the data operator is a ParamsFetcher
+func (b *DetailingBuilder) fetchWithAssociations(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error) {
+	pf, ok := b.mb.p.dataOperator.(ParamsFetcher)
+	if !ok {
+		return b.mb.p.dataOperator.Fetch(obj, id, ctx)
+	}
+	return pf.FetchWithParams(obj, id, &FetchParams{Associations: b.mb.manyToManyFields(b.fields)}, ctx)
+}
+
 func (b *DetailingBuilder) GetPageFunc() web.PageFunc {
 	if b.pageFunc != nil {
 		return b.pageFunc
@@ -1,6 +1,9 @@
 package presets
 
 import (
+	"errors"
+
+	"github.com/go-playground/form"
 	"github.com/goplaid/web"
 	"github.com/goplaid/x/i18n"
 	"github.com/goplaid/x/perm"
@@ -18,6 +21,10 @@ type EditingBuilder struct {
 	saver     SaveFunc
 	deleter   DeleteFunc
 	validator ValidateFunc
+	hasMany   []*HasManyBuilder
+	// lockVersion is the version field of the optimistic locking
+	lockVersion string
+	fullPage    bool
 	FieldBuilders
 }
 
@@ -52,6 +59,12 @@ func (b *EditingBuilder) Creating(vs ...string) (r *EditingBuilder) {
 	r = b.mb.creating
 
 	r.FieldBuilders = *b.mb.writeFields.Only(vs...)
+	for _, hm := range b.hasMany {
+		if f := r.GetField(hm.fieldName); f != nil {
+			f.ComponentFunc(hm.component)
+			r.hasMany = append(r.hasMany, hm)
+		}
+	}
 
 	return r
 }
@@ -82,6 +95,11 @@ func (b *EditingBuilder) SetterFunc(v SetterFunc) (r *EditingBuilder) {
 }
 
 func (b *EditingBuilder) formDrawerNew(ctx *web.EventContext) (r web.EventResponse, err error) {
+	if b.fullPage {
+		r.PushState = web.PushState(pageParamsQuery(ctx)).URL(b.mb.Info().NewingHref())
+		return
+	}
+
 	creatingB := b
 	if b.mb.creating != nil {
 		creatingB = b.mb.creating
@@ -92,10 +110,24 @@ func (b *EditingBuilder) formDrawerNew(ctx *web.EventContext) (r web.EventRespon
 }
 
 func (b *EditingBuilder) formDrawerEdit(ctx *web.EventContext) (r web.EventResponse, err error) {
+	if b.fullPage {
+		r.PushState = web.PushState(pageParamsQuery(ctx)).URL(b.mb.Info().EditingHref(ctx.Event.Params[0]))
+		return
+	}
+
 	b.mb.p.rightDrawer(&r, b.editFormFor(nil, ctx))
 	return
 }
 
+func (b *EditingBuilder) formTitle(obj interface{}, id string, ctx *web.EventContext) string {
+	msgr := MustGetMessages(ctx.R)
+	modelName := i18n.T(ctx.R, ModelsI18nModuleKey, inflection.Singular(b.mb.label))
+	if len(id) == 0 {
+		return msgr.CreatingObjectTitle(modelName)
+	}
+	return msgr.EditingObjectTitle(modelName, getPageTitle(obj, id))
+}
+
 func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.HTMLComponent {
 	msgr := MustGetMessages(ctx.R)
 
@@ -103,9 +135,6 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 
 	var buttonLabel = msgr.Create
 	var disableUpdateBtn bool
-	var title = msgr.CreatingObjectTitle(
-		i18n.T(ctx.R, ModelsI18nModuleKey, inflection.Singular(b.mb.label)),
-	)
 	if len(id) > 0 {
 		if obj == nil {
 			var err error
@@ -116,10 +145,8 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 		}
 		disableUpdateBtn = b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).WithReq(ctx.R).IsAllowed() != nil
 		buttonLabel = msgr.Update
-		title = msgr.EditingObjectTitle(
-			i18n.T(ctx.R, ModelsI18nModuleKey, inflection.Singular(b.mb.label)),
-			getPageTitle(obj, id))
 	}
+	title := b.formTitle(obj, id, ctx)
 
 	if obj == nil {
 		obj = b.mb.newModel()
@@ -132,6 +159,25 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 
 	vErr, _ := ctx.Flash.(*web.ValidationErrors)
 
+	updateBtn := VBtn(buttonLabel).
+		Dark(true).
+		Color("primary").
+		Disabled(disableUpdateBtn).
+		Attr("@click", web.Plaid().
+			EventFunc(actions.Update, ctx.Event.Params...).
+			URL(b.mb.Info().ListingHref()).
+			Go())
+
+	form := h.Components(
+		b.lockVersionInput(obj, id),
+		b.mb.listing.tree.newParentInput(id, ctx),
+		b.ToComponent(b.mb, obj, vErr, ctx),
+	)
+
+	if b.mb.editing.fullPage {
+		return b.editPageBody(title, notice, form, updateBtn, ctx)
+	}
+
 	return h.Components(
 		VAppBar(
 			VToolbarTitle(title).Class("pl-2"),
@@ -146,18 +192,11 @@ func (b *EditingBuilder) editFormFor(obj interface{}, ctx *web.EventContext) h.H
 			notice,
 			VCard(
 				VCardText(
-					b.ToComponent(b.mb, obj, vErr, ctx),
+					form,
 				),
 				VCardActions(
 					VSpacer(),
-					VBtn(buttonLabel).
-						Dark(true).
-						Color("primary").
-						Disabled(disableUpdateBtn).
-						Attr("@click", web.Plaid().
-							EventFunc(actions.Update, ctx.Event.Params...).
-							URL(b.mb.Info().ListingHref()).
-							Go()),
+					updateBtn,
 				),
 			).Flat(true),
 		).Fluid(true),
@@ -172,6 +211,7 @@ func (b *EditingBuilder) doDelete(ctx *web.EventContext) (r web.EventResponse, e
 		if err != nil {
 			return
 		}
+		b.mb.notifyChange(id, ChangeDeleted, ctx)
 	}
 
 	r.PushState = web.PushState(nil)
@@ -180,87 +220,203 @@ func (b *EditingBuilder) doDelete(ctx *web.EventContext) (r web.EventResponse, e
 
 func (b *EditingBuilder) defaultUpdate(ctx *web.EventContext) (r web.EventResponse, err error) {
 	id := ctx.Event.Params[0]
+	usingB := b
+	if b.mb.creating != nil && len(id) == 0 {
+		usingB = b.mb.creating
+	}
+
+	obj, err1 := usingB.fetchAndSet(id, ctx)
+	if err1 != nil {
+		usingB.keepLockVersion(obj, ctx)
+		b.renderFormWithError(&r, err1, obj, ctx)
+		return
+	}
+
+	err1 = usingB.checkLockVersion(obj, id, ctx)
+	if err1 != nil {
+		b.renderFormWithError(&r, err1, obj, ctx)
+		return
+	}
+
+	err1 = usingB.save(obj, id, ctx)
+	if err1 != nil {
+		usingB.keepLockVersion(obj, ctx)
+		b.renderFormWithError(&r, err1, obj, ctx)
+		return
+	}
+
+	msgr := MustGetMessages(ctx.R)
+	ctx.Flash = msgr.SuccessfullyUpdated
+
+	if b.fullPage {
+		r.PushState = web.PushState(nil).URL(b.pageBackHref(id))
+		return
+	}
+
+	r.PushState = web.PushState(nil)
+	r.VarsScript = `vars.rightDrawer = false`
+	return
+}
+
+// fetchAndSet fetches the object of id or creates a new one when id is empty, checks the permissions,
+// and sets the submitted form of ctx to it with the setter and field setters, then validates it.
+// the returned err is a *web.ValidationErrors when the form is invalid
+func (b *EditingBuilder) fetchAndSet(id string, ctx *web.EventContext) (obj interface{}, err error) {
 	var newObj = b.mb.newModel()
 	// don't panic for fields that set in SetterFunc
-	_ = ctx.UnmarshalForm(newObj)
+	decodeErrs := unmarshalForm(newObj, ctx)
 
 	if len(id) == 0 {
 		if b.mb.Info().Verifier().Do(PermCreate).ObjectOn(newObj).WithReq(ctx.R).IsAllowed() != nil {
-			b.renderFormWithError(&r, perm.PermissionDenied, newObj, ctx)
-			return
+			return newObj, perm.PermissionDenied
 		}
 	}
 
-	var obj = b.mb.newModel()
-	usingB := b
-	if b.mb.creating != nil && len(id) == 0 {
-		usingB = b.mb.creating
-	}
+	obj = b.mb.newModel()
 
 	if len(id) > 0 {
-		obj, err1 := usingB.fetcher(obj, id, ctx)
-		if err1 != nil {
-			b.renderFormWithError(&r, err1, obj, ctx)
+		obj, err = b.fetcher(obj, id, ctx)
+		if err != nil {
 			return
 		}
 		if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).WithReq(ctx.R).IsAllowed() != nil {
-			b.renderFormWithError(&r, perm.PermissionDenied, obj, ctx)
+			return obj, perm.PermissionDenied
+		}
+	}
+
+	if len(id) == 0 {
+		err = b.mb.listing.tree.setNewParent(obj, ctx)
+		if err != nil {
 			return
 		}
 	}
 
-	if usingB.setter != nil {
-		usingB.setter(obj, ctx)
+	if b.setter != nil {
+		b.setter(obj, ctx)
 	}
 
 	var vErr web.ValidationErrors
-	for _, f := range usingB.fields {
+	for _, f := range b.fields {
 
 		if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn(f.name).WithReq(ctx.R).IsAllowed() != nil {
 			continue
 		}
 
+		if hm := b.hasManyField(f.name); hm != nil {
+			hm.set(obj, newObj, &vErr, ctx)
+			continue
+		}
+
 		if f.setterFunc == nil {
+			if _, ok := decodeErrs[f.name]; ok {
+				vErr.FieldError(f.name, MustGetMessages(ctx.R).InvalidFieldValue)
+				continue
+			}
 			_ = reflectutils.Set(obj, f.name, reflectutils.MustGet(newObj, f.name))
-			continue
+		} else {
+			err1 := f.setterFunc(obj, &FieldContext{
+				ModelInfo: b.mb.Info(),
+				Name:      f.name,
+				Label:     b.getLabel(f.NameLabel),
+				Context:   f.context,
+			}, ctx)
+			if err1 != nil {
+				vErr.FieldError(f.name, err1.Error())
+				continue
+			}
 		}
 
-		err1 := f.setterFunc(obj, &FieldContext{
-			ModelInfo: b.mb.Info(),
-			Name:      f.name,
-			Label:     b.getLabel(f.NameLabel),
-		}, ctx)
-		if err1 != nil {
-			vErr.FieldError(f.name, err1.Error())
+		if msg := validateField(MustGetMessages(ctx.R), obj, f.name); len(msg) > 0 {
+			vErr.FieldError(f.name, msg)
 		}
 	}
 
 	if vErr.HaveErrors() {
-		b.renderFormWithError(&r, &vErr, obj, ctx)
-		return
+		return obj, &vErr
 	}
 
-	if usingB.validator != nil {
-		if vErr := usingB.validator(obj, ctx); vErr.HaveErrors() {
-			b.renderFormWithError(&r, &vErr, obj, ctx)
-			return
+	if b.validator != nil {
+		if vErr := b.validator(obj, ctx); vErr.HaveErrors() {
+			return obj, &vErr
 		}
 	}
 
-	err1 := usingB.saver(obj, id, ctx)
-	if err1 != nil {
-		b.renderFormWithError(&r, err1, obj, ctx)
+	return
+}
+
+// unmarshalForm decodes the form to obj, and returns the errors of the values that can't be decoded
+// like text for number fields by field names, which UnmarshalForm panics with
+func unmarshalForm(obj interface{}, ctx *web.EventContext) (errs form.DecodeErrors) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			var ok bool
+			if errs, ok = rec.(form.DecodeErrors); !ok {
+				panic(rec)
+			}
+		}
+	}()
+	_ = ctx.UnmarshalForm(obj)
+	return
+}
+
+// save saves the object, and notifies the open listings of the model
+func (b *EditingBuilder) save(obj interface{}, id string, ctx *web.EventContext) (err error) {
+	err = b.saveObject(obj, id, ctx)
+	if err == ErrLockConflict {
+		return errors.New(MustGetMessages(ctx.R).LockConflictRetry)
+	}
+	if err != nil {
 		return
 	}
 
-	msgr := MustGetMessages(ctx.R)
-	ctx.Flash = msgr.SuccessfullyUpdated
-
-	r.PushState = web.PushState(nil)
-	r.VarsScript = `vars.rightDrawer = false`
+	t := ChangeUpdated
+	if len(id) == 0 {
+		t = ChangeCreated
+		id = b.mb.objectID(obj)
+	}
+	b.mb.notifyChange(id, t, ctx)
 	return
 }
 
+// saveObject saves the object with the SaveFunc, or with the data operator if it's not set, which updates the
+// fields of the builder even if they are set to zero values, and the children of the has-many fields of the builder
+// when it's a ParamsSaver
+func (b *EditingBuilder) saveObject(obj interface{}, id string, ctx *web.EventContext) (err error) {
+	lockVersion := b.nextLockVersion(obj, id)
+	if b.saver != nil {
+		return b.saver(obj, id, ctx)
+	}
+
+	ps, ok := b.mb.p.dataOperator.(ParamsSaver)
+	if !ok {
+		return b.mb.p.dataOperator.Save(obj, id, ctx)
+	}
+	var fields []string
+	for _, f := range b.fields {
+		fields = append(fields, f.name)
+	}
+	return ps.SaveWithParams(obj, id, &SaveParams{Fields: fields, Associations: b.associations(), LockVersion: lockVersion}, ctx)
+}
+
+// fetchWithAssociations is the default FetchFunc, it fetches the object with the children of the has-many fields
+// of the form if the data operator is a ParamsFetcher
+func (b *EditingBuilder) fetchWithAssociations(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error) {
+	pf, ok := b.mb.p.dataOperator.(ParamsFetcher)
+	if !ok {
+		return b.mb.p.dataOperator.Fetch(obj, id, ctx)
+	}
+	return pf.FetchWithParams(obj, id, &FetchParams{Associations: b.associations()}, ctx)
+}
+
+// associations are the has-many fields registered with HasMany and the many-to-many fields of the form,
+// that are fetched and saved with the object
+func (b *EditingBuilder) associations() (r []string) {
+	for _, hm := range b.hasMany {
+		r = append(r, hm.fieldName)
+	}
+	return append(r, b.mb.manyToManyFields(b.fields)...)
+}
+
 func (b *EditingBuilder) renderFormWithError(r *web.EventResponse, err error, obj interface{}, ctx *web.EventContext) {
 	ctx.Flash = err
 
@@ -271,7 +427,7 @@ func (b *EditingBuilder) renderFormWithError(r *web.EventResponse, err error, ob
 	}
 
 	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
-		Name: rightDrawerContentPortalName,
+		Name: b.formPortalName(),
 		Body: b.editFormFor(obj, ctx),
 	})
 
@@ -0,0 +1,180 @@
+package presets
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/goplaid/web"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// LockVersion enables the optimistic locking of the edits, the form carries the version field of the record it's opened
+// with, and the update is rejected if the record is saved by others after that, the fields different from the saved ones
+// are shown in the form. the version field is an integer that's incremented, or a time.Time like UpdatedAt that's set
+// to now on every update of the forms, inline edits, boards, calendars and trees, which the data operator only saves
+// if the version isn't changed since it's fetched when it's a ParamsSaver
+func (b *EditingBuilder) LockVersion(fieldName string) (r *EditingBuilder) {
+	if t := reflectutils.GetType(b.mb.model, fieldName); t == nil || t != reflect.TypeOf(time.Time{}) && !isIntegerKind(t.Kind()) {
+		panic(fmt.Sprintf("lock version %s of %s must be an integer or time.Time", fieldName, b.mb.label))
+	}
+	b.lockVersion = fieldName
+	return b
+}
+
+const lockVersionFieldName = "__LockVersion"
+
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func lockVersionString(obj interface{}, fieldName string) string {
+	if t, ok := reflectutils.MustGet(obj, fieldName).(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(reflectutils.MustGet(obj, fieldName))
+}
+
+// lockVersionInput is the hidden field of the version that the form is opened with
+func (b *EditingBuilder) lockVersionInput(obj interface{}, id string) h.HTMLComponent {
+	if len(b.lockVersion) == 0 || len(id) == 0 {
+		return nil
+	}
+	return h.Input("").Type("hidden").
+		Value(lockVersionString(obj, b.lockVersion)).
+		Attr(web.VFieldName(lockVersionFieldName)...)
+}
+
+// keepLockVersion sets the version in the form to the object, that the form shown again still carries the version
+// it's opened with, instead of the one fetched
+func (b *EditingBuilder) keepLockVersion(obj interface{}, ctx *web.EventContext) {
+	vs, ok := ctx.R.Form[lockVersionFieldName]
+	if len(b.lockVersion) == 0 || obj == nil || !ok || len(vs) == 0 {
+		return
+	}
+
+	t := reflectutils.GetType(obj, b.lockVersion)
+	if t == reflect.TypeOf(time.Time{}) {
+		if v, err := time.Parse(time.RFC3339Nano, vs[0]); err == nil {
+			_ = reflectutils.Set(obj, b.lockVersion, v)
+		}
+		return
+	}
+
+	if v, err := strconv.ParseInt(vs[0], 10, 64); err == nil {
+		_ = reflectutils.Set(obj, b.lockVersion, reflect.ValueOf(v).Convert(t).Interface())
+	}
+}
+
+// nextLockVersion sets the next version to the object that's updated, r is the version that it's fetched with
+func (b *EditingBuilder) nextLockVersion(obj interface{}, id string) (r *LockVersionParams) {
+	if len(b.lockVersion) == 0 || len(id) == 0 {
+		return
+	}
+
+	version := reflectutils.MustGet(obj, b.lockVersion)
+	r = &LockVersionParams{Field: b.lockVersion, Version: version}
+	v := reflect.ValueOf(version)
+	if !isIntegerKind(v.Kind()) {
+		_ = reflectutils.Set(obj, b.lockVersion, time.Now())
+		return
+	}
+
+	next := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		next.SetUint(v.Uint() + 1)
+	default:
+		next.SetInt(v.Int() + 1)
+	}
+	_ = reflectutils.Set(obj, b.lockVersion, next.Interface())
+	return
+}
+
+// checkLockVersion compares the version in the form with the saved one, the form is shown again with the saved version
+// and the different fields on conflicts, that it's overwritten if it's updated again. it's not checked if the version
+// isn't in the form
+func (b *EditingBuilder) checkLockVersion(obj interface{}, id string, ctx *web.EventContext) (err error) {
+	vs, ok := ctx.R.Form[lockVersionFieldName]
+	if len(b.lockVersion) == 0 || len(id) == 0 || !ok || len(vs) == 0 {
+		return
+	}
+
+	saved, err := b.fetcher(b.mb.newModel(), id, ctx)
+	if err != nil {
+		return
+	}
+
+	savedVersion := reflectutils.MustGet(saved, b.lockVersion)
+	if lockVersionString(saved, b.lockVersion) == vs[0] {
+		return
+	}
+
+	_ = reflectutils.Set(obj, b.lockVersion, savedVersion)
+
+	msgr := MustGetMessages(ctx.R)
+	vErr := &web.ValidationErrors{}
+	vErr.GlobalError(msgr.LockConflict)
+	for _, f := range b.fields {
+		if b.hasManyField(f.name) != nil {
+			continue
+		}
+		if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn(f.name).WithReq(ctx.R).IsAllowed() != nil {
+			continue
+		}
+
+		field := &FieldContext{
+			ModelInfo: b.mb.Info(),
+			Name:      f.name,
+			Label:     b.getLabel(f.NameLabel),
+			Context:   f.context,
+		}
+		savedText, ok := lockConflictText(saved, field, ctx)
+		if !ok {
+			continue
+		}
+		if text, _ := lockConflictText(obj, field, ctx); text != savedText {
+			vErr.FieldError(f.name, msgr.LockConflictSavedValue(savedText))
+		}
+	}
+	return vErr
+}
+
+// lockConflictText is the text of the field to compare and show on conflicts, it's only for the fields of
+// the basic types, the times and the selectable types
+func lockConflictText(obj interface{}, field *FieldContext, ctx *web.EventContext) (text string, ok bool) {
+	t := reflectutils.GetType(obj, field.Name)
+	if t == reflect.TypeOf(time.Time{}) || t == reflect.TypeOf(&time.Time{}) {
+		return formatTimeField(obj, field, ctx), true
+	}
+	if isSelectable(t) {
+		return selectText(obj, field, ctx), true
+	}
+
+	v := reflect.ValueOf(reflectutils.MustGet(obj, field.Name))
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", isBasicKind(t.Elem().Kind())
+		}
+		v = v.Elem()
+	}
+	if !isBasicKind(v.Kind()) {
+		return "", false
+	}
+	return fmt.Sprint(v.Interface()), true
+}
+
+func isBasicKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return isIntegerKind(k)
+}
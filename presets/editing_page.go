@@ -0,0 +1,113 @@
+package presets
+
+import (
+	"net/url"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/perm"
+	. "github.com/goplaid/x/vuetify"
+	h "github.com/theplant/htmlgo"
+	"goji.io/pat"
+)
+
+// FullPage creates and edits the records in their own pages of ModelInfo.NewingHref and EditingHref instead of
+// the right drawer, that are in the history of the browser, with the same fields, setters, validations and permissions
+func (b *EditingBuilder) FullPage(v bool) (r *EditingBuilder) {
+	b.mb.editing.fullPage = v
+	return b
+}
+
+const editingPagePortalName = "editingPagePortalName"
+
+// formPortalName is the portal that the form is shown in again with the errors
+func (b *EditingBuilder) formPortalName() string {
+	if b.mb.editing.fullPage {
+		return editingPagePortalName
+	}
+	return rightDrawerContentPortalName
+}
+
+// pageParamsQuery keeps the event params after the id and the parent of the new tree nodes in the url of the page
+func pageParamsQuery(ctx *web.EventContext) (r url.Values) {
+	r = url.Values{}
+	if len(ctx.Event.Params) >= 2 {
+		r["params"] = ctx.Event.Params[1:]
+	}
+	if parentID := ctx.R.FormValue(treeNewParentFieldName); len(parentID) > 0 {
+		r.Set(treeNewParentFieldName, parentID)
+	}
+	return
+}
+
+// pageBackHref is the page that the editing page goes back to when it's saved or canceled
+func (b *EditingBuilder) pageBackHref(id string) string {
+	if b.mb.hasDetailing && len(id) > 0 {
+		return b.mb.Info().DetailingHref(id)
+	}
+	return b.mb.Info().ListingHref()
+}
+
+func (b *EditingBuilder) newPageFunc(ctx *web.EventContext) (r web.PageResponse, err error) {
+	return b.pageFunc("", ctx)
+}
+
+func (b *EditingBuilder) editPageFunc(ctx *web.EventContext) (r web.PageResponse, err error) {
+	return b.pageFunc(pat.Param(ctx.R, "id"), ctx)
+}
+
+func (b *EditingBuilder) pageFunc(id string, ctx *web.EventContext) (r web.PageResponse, err error) {
+	usingB := b
+	if b.mb.creating != nil && len(id) == 0 {
+		usingB = b.mb.creating
+	}
+	// the form is the same as the one of the drawer events
+	ctx.Event = &web.Event{Params: append([]string{id}, ctx.R.URL.Query()["params"]...)}
+
+	var obj interface{}
+	if len(id) == 0 {
+		if b.mb.Info().Verifier().Do(PermCreate).WithReq(ctx.R).IsAllowed() != nil {
+			err = perm.PermissionDenied
+			return
+		}
+	} else {
+		obj, err = usingB.fetcher(b.mb.newModel(), id, ctx)
+		if err != nil {
+			return
+		}
+		if b.mb.Info().Verifier().Do(PermGet).ObjectOn(obj).WithReq(ctx.R).IsAllowed() != nil {
+			err = perm.PermissionDenied
+			return
+		}
+	}
+
+	r.PageTitle = usingB.formTitle(obj, id, ctx)
+	r.Body = web.Portal(usingB.editFormFor(obj, ctx)).Name(editingPagePortalName)
+	return
+}
+
+// editPageBody is the form in the page, the toolbar of the buttons sticks under the app bar, that the long forms
+// are saved without scrolling to the end
+func (b *EditingBuilder) editPageBody(title string, notice h.HTMLComponent, form h.HTMLComponent, updateBtn h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+	back := web.Plaid().PushStateURL(b.pageBackHref(ctx.Event.Params[0])).Go()
+
+	return VContainer(
+		notice,
+		VToolbar(
+			VBtn("").Icon(true).Children(
+				VIcon("arrow_back"),
+			).Attr("@click", back),
+			VToolbarTitle(title),
+			VSpacer(),
+			VBtn(msgr.Cancel).Text(true).Class("mr-2").Attr("@click", back),
+			updateBtn,
+		).Flat(true).
+			Dense(true).
+			Attr("style", "position: sticky; top: 64px; z-index: 2"),
+		VCard(
+			VCardText(
+				form,
+			),
+		).Outlined(true),
+	).Attr("style", "max-width: 960px")
+}
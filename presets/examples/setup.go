@@ -2,7 +2,11 @@ package examples
 
 import (
 	"fmt"
+	"log"
+	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 	"github.com/goplaid/x/presets"
 	"github.com/goplaid/x/presets/actions"
 	"github.com/goplaid/x/presets/gorm2op"
+	"github.com/goplaid/x/presets/storage"
 	s "github.com/goplaid/x/stripeui"
 	. "github.com/goplaid/x/vuetify"
 	"github.com/goplaid/x/vuetifyx"
@@ -37,6 +42,7 @@ type Customer struct {
 	ApprovalComment string
 	LanguageCode    string
 	Events          []*Event `gorm:"-"`
+	CreditCards     []*CreditCard
 }
 
 func (c *Customer) PageTitle() string {
@@ -55,14 +61,24 @@ type Note struct {
 type CreditCard struct {
 	ID              int
 	CustomerID      int
-	Number          string
+	Number          string `presets:"required"`
 	ExpireYearMonth string
 	Name            string
-	Type            string
+	Type            CardType
 	Phone           string
 	Email           string
 }
 
+type CardType string
+
+func (CardType) Options(ctx *web.EventContext) []presets.SelectItem {
+	return []presets.SelectItem{
+		{Text: "Visa", Value: "visa"},
+		{Text: "Mastercard", Value: "mastercard"},
+		{Text: "American Express", Value: "amex"},
+	}
+}
+
 type Payment struct {
 	ID                   int
 	CustomerID           int
@@ -101,13 +117,57 @@ func (l *Language) PrimaryColumnValuesBySlug(slug string) [][]string {
 
 type Company struct {
 	ID   int
-	Name string
+	Name string `presets:"required"`
+}
+
+type Ticket struct {
+	ID       int
+	Title    string `presets:"required,max=100"`
+	Assignee string
+	Status   string `presets:"oneof=open in_progress done"`
+}
+
+func (t *Ticket) PageTitle() string {
+	return t.Title
+}
+
+type Appointment struct {
+	ID      int
+	Title   string
+	StartAt time.Time
+	EndAt   *time.Time
+}
+
+func (a *Appointment) PageTitle() string {
+	return a.Title
+}
+
+type Category struct {
+	ID       int
+	Name     string
+	ParentID int
+}
+
+func (c *Category) PageTitle() string {
+	return c.Name
 }
 
 type Product struct {
-	ID        int
-	Name      string
-	OwnerName string
+	ID          int
+	Name        string
+	OwnerName   string
+	Manual      presets.Attachment
+	Description presets.RichText
+	Tags        []*Tag `gorm:"many2many:product_tags"`
+}
+
+type Tag struct {
+	ID   int
+	Name string
+}
+
+func (t *Tag) PageTitle() string {
+	return t.Name
 }
 
 func Preset1(db *gorm.DB) (r *presets.Builder) {
@@ -119,6 +179,10 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 		&Event{},
 		&Company{},
 		&Product{},
+		&Tag{},
+		&Ticket{},
+		&Appointment{},
+		&Category{},
 		&Language{},
 	)
 	if err != nil {
@@ -180,14 +244,84 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 			).Class("mb-4")
 	})
 
-	p.DataOperator(gorm2op.DataOperator(db))
+	op := gorm2op.DataOperator(db)
+	p.DataOperator(op)
+
+	// the example has no login, all the visitors share the views and columns of one user
+	p.ListingViewStore(gorm2op.ListingViewStore(db).AutoMigrate()).
+		ListingColumnsStore(gorm2op.ListingColumnsStore(db).AutoMigrate()).
+		UserIDFunc(func(r *http.Request) string {
+			return "admin"
+		})
+
+	p.AttachmentStorage(storage.Local(filepath.Join(os.TempDir(), "presets-example-attachments")))
 
 	p.MenuGroup("Customer Management").Icon("group")
+	p.ManyToMany([]*Tag{})
 	mp := p.Model(&Product{}).MenuIcon("laptop")
-	mp.Listing().PerPage(3)
+	mp.Listing().PerPage(3).CursorPagination(true)
+	p.Model(&Tag{}).MenuIcon("label").Listing().SearchColumns("name")
+
+	// sqlite has fts5 only when it's built with the sqlite_fts5 tag, the tickets are searched with LIKE without it
+	var ticketsFullText presets.FullTextSearch = presets.PostgresFullText("simple")
+	if db.Dialector.Name() == "sqlite" {
+		ticketsFullText = presets.SQLiteFullText()
+	}
+	ticketsSearchColumns := []string{"title", "assignee"}
+	for _, sql := range ticketsFullText.Setup("tickets", ticketsSearchColumns) {
+		if err := db.Exec(sql).Error; err != nil {
+			log.Println("full-text search of tickets disabled:", err)
+			for _, sql := range ticketsFullText.Teardown("tickets") {
+				db.Exec(sql)
+			}
+			ticketsFullText = nil
+			break
+		}
+	}
+
+	tm := p.Model(&Ticket{}).MenuIcon("support")
+	tl := tm.Listing("Title", "Assignee", "Status").
+		SearchColumns(ticketsSearchColumns...).
+		HighlightKeyword("Title").
+		LiveUpdates(presets.LiveUpdatesBanner)
+	if ticketsFullText != nil {
+		tl.FullTextSearch(ticketsFullText)
+	}
+	tl.Kanban("Status").
+		Column("open", "Open").
+		Column("in_progress", "In Progress").
+		Column("done", "Done").
+		Default(true)
+	tm.Editing("Title", "Assignee", "Status").
+		ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
+			t := obj.(*Ticket)
+			if t.Status != "open" && len(t.Assignee) == 0 {
+				err.GlobalError("assign the ticket before starting it")
+			}
+			return
+		})
+
+	am := p.Model(&Appointment{}).MenuIcon("event")
+	am.Listing("Title").
+		Calendar("StartAt").
+		EndField("EndAt").
+		Default(true)
+	am.Editing("Title", "StartAt", "EndAt")
+
+	cm := p.Model(&Category{}).MenuIcon("account_tree")
+	cm.Listing("Name").
+		Tree("ParentID").
+		Default(true)
+	cm.Editing("Name")
 
 	m := p.Model(&Customer{}).URIName("my_customers").MenuGroup("Customer Management")
-	p.Model(&Company{}).MenuGroup("Customer Management")
+	p.Model(&Company{}).MenuGroup("Customer Management").
+		Editing().FullPage(true)
+	p.Model(&Payment{}).MenuGroup("Customer Management").
+		Listing("ID", "CustomerID", "CurrencyCode", "Amount", "Description").
+		Aggregate("ID", presets.AggregateCount).
+		Aggregate("Amount", presets.AggregateSum, presets.AggregateAvg).
+		GroupBy("CurrencyCode")
 	m.Labels(
 		"Name", "名字",
 		"Bool1", "性别",
@@ -197,7 +331,12 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 		"Name", "请输入你的名字",
 	)
 
-	l := m.Listing("Name", "CompanyID", "ApprovalComment").SearchColumns("name", "email", "description").PerPage(5)
+	l := m.Listing("Name", "CompanyID", "ApprovalComment").SearchColumns("name", "email", "description").PerPage(5).
+		SortableFields("Name", "ApprovalComment").
+		ExportFormats(presets.ExportCSV, presets.ExportXLSX).
+		InlineEditFields("Name", "CompanyID").
+		ColumnChooser(true).
+		ChoosableColumns("ID", "Email", "Description", "CreatedAt")
 	l.Field("Name").Label("列表的名字")
 	l.Field("CompanyID").ComponentFunc(func(obj interface{}, field *presets.FieldContext, ctx *web.EventContext) h.HTMLComponent {
 		u := obj.(*Customer)
@@ -213,21 +352,35 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 						EventFunc(actions.DrawerEdit, fmt.Sprint(comp.ID)).
 						Go()),
 		)
+	}).ExportFunc(func(obj interface{}, field *presets.FieldContext, ctx *web.EventContext) interface{} {
+		u := obj.(*Customer)
+		var comp Company
+		err := db.Find(&comp, u.CompanyID).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			panic(err)
+		}
+		if comp.ID == 0 {
+			return u.CompanyID
+		}
+		return comp.Name
 	})
 
-	l.BulkAction("Approve").Label("Approve").UpdateFunc(func(selectedIds []string, ctx *web.EventContext) (err error) {
+	approve := func(wh *gorm.DB, ctx *web.EventContext) (err error) {
 		comment := ctx.R.FormValue("ApprovalComment")
 		if len(comment) < 10 {
 			ctx.Flash = "comment should larger than 10"
 			return
 		}
-		err = db.Model(&Customer{}).
-			Where("id IN (?)", selectedIds).
-			Updates(map[string]interface{}{"approved_at": time.Now(), "approval_comment": comment}).Error
+		err = wh.Updates(map[string]interface{}{"approved_at": time.Now(), "approval_comment": comment}).Error
 		if err != nil {
 			ctx.Flash = err.Error()
 		}
 		return
+	}
+	l.BulkAction("Approve").Label("Approve").UpdateFunc(func(selectedIds []string, ctx *web.EventContext) (err error) {
+		return approve(db.Model(&Customer{}).Where("id IN (?)", selectedIds), ctx)
+	}).UpdateMatchingFunc(func(searchParams *presets.SearchParams, ctx *web.EventContext) (err error) {
+		return approve(op.SearchWhere(&Customer{}, searchParams), ctx)
 	}).ComponentFunc(func(selectedIds []string, ctx *web.EventContext) h.HTMLComponent {
 		comment := ctx.R.FormValue("ApprovalComment")
 		errorMessage := ""
@@ -307,7 +460,9 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 		}
 	})
 
-	ef := m.Editing("Name", "CompanyID", "LanguageCode").
+	m.Importing("Name", "CompanyID")
+
+	ef := m.Editing("Name", "CompanyID", "LanguageCode", "CreditCards").
 		ValidateFunc(func(obj interface{}, ctx *web.EventContext) (err web.ValidationErrors) {
 			cu := obj.(*Customer)
 			if len(cu.Name) < 5 {
@@ -316,6 +471,8 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 			}
 			return
 		})
+	ef.LockVersion("UpdatedAt")
+	ef.HasMany("CreditCards", "Number", "ExpireYearMonth", "Name")
 	ef.Field("LanguageCode").Label("语言").ComponentFunc(func(obj interface{}, field *presets.FieldContext, ctx *web.EventContext) h.HTMLComponent {
 		u := obj.(*Customer)
 		var langs []Language
@@ -483,7 +640,7 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 						s.DetailField(s.OptionalText(card.Name).ZeroLabel("No Name")).Label("Name"),
 						s.DetailField(s.OptionalText(card.Number).ZeroLabel("No Number")).Label("Number"),
 						s.DetailField(s.OptionalText(card.ExpireYearMonth).ZeroLabel("No Expires")).Label("Expires"),
-						s.DetailField(s.OptionalText(card.Type).ZeroLabel("No Type")).Label("Type"),
+						s.DetailField(s.OptionalText(string(card.Type)).ZeroLabel("No Type")).Label("Type"),
 						s.DetailField(s.OptionalText(card.Phone).ZeroLabel("No phone provided")).Label("Phone"),
 						s.DetailField(s.OptionalText(card.Email).ZeroLabel("No email provided")).Label("Email"),
 					),
@@ -557,7 +714,7 @@ func Preset1(db *gorm.DB) (r *presets.Builder) {
 	cc := p.Model(&CreditCard{}).
 		InMenu(false)
 
-	ccedit := cc.Editing("ExpireYearMonth", "Phone", "Email").
+	ccedit := cc.Editing("Type", "ExpireYearMonth", "Phone", "Email").
 		SetterFunc(func(obj interface{}, ctx *web.EventContext) {
 			card := obj.(*CreditCard)
 			card.CustomerID = ctx.Event.ParamAsInt(1)
@@ -0,0 +1,234 @@
+package presets
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/i18n"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
+const exportFormatParamName = "format"
+const exportBatchSize = 500
+
+type exportRowWriter interface {
+	Write(cells []interface{}) error
+	Close() error
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvRowWriter) Write(cells []interface{}) error {
+	var record []string
+	for _, c := range cells {
+		record = append(record, fmt.Sprint(c))
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvRowWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// ExportFormats adds an export menu to the listing toolbar, that downloads all the records
+// matching the current keyword, filters and order in these formats
+func (b *ListingBuilder) ExportFormats(vs ...ExportFormat) (r *ListingBuilder) {
+	b.exportFormats = vs
+	return b
+}
+
+func (b *ListingBuilder) exportHref(format ExportFormat, ctx *web.EventContext) string {
+	q := ctx.R.URL.Query()
+	q.Del("page")
+	q.Del(selectedParamName)
+	q.Set(exportFormatParamName, string(format))
+	return fmt.Sprintf("%s/export?%s", b.mb.Info().ListingHref(), q.Encode())
+}
+
+func (b *ListingBuilder) exportMenu(msgr *Messages, ctx *web.EventContext) h.HTMLComponent {
+	if len(b.exportFormats) == 0 {
+		return nil
+	}
+
+	var items []h.HTMLComponent
+	for _, f := range b.exportFormats {
+		items = append(items,
+			VListItem(
+				VListItemTitle(h.Text(msgr.ExportAs(string(f)))),
+			).Href(b.exportHref(f, ctx)),
+		)
+	}
+
+	return VMenu(
+		web.Slot(
+			VBtn(msgr.Export).
+				Depressed(true).
+				Class("ml-2").
+				Attr("v-on", "on"),
+		).Name("activator").Scope("{ on }"),
+		VList(items...).Dense(true),
+	).OffsetY(true)
+}
+
+func (b *ListingBuilder) exportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := &web.EventContext{R: r, W: w, Event: &web.Event{}}
+
+	if b.mb.Info().Verifier().Do(PermList).WithReq(r).IsAllowed() != nil {
+		http.Error(w, "permission denied", http.StatusForbidden)
+		return
+	}
+
+	format := ExportFormat(r.URL.Query().Get(exportFormatParamName))
+	if !b.canExport(format) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if b.searcher == nil || b.mb.p.dataOperator == nil {
+		panic("presets.New().DataOperator(...) required")
+	}
+
+	searchParams, _ := b.searchParamsFromQuery(ctx)
+	searchParams.PerPage = exportBatchSize
+
+	label := i18n.T(r, ModelsI18nModuleKey, b.mb.label)
+	filename := fmt.Sprintf("%s-%s.%s", b.mb.uriName, time.Now().Format("20060102150405"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q; filename*=UTF-8''%s", filename, url.PathEscape(filename)))
+
+	var rw exportRowWriter
+	switch format {
+	case ExportCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		// utf-8 BOM makes Excel detect the encoding of translated headers
+		_, _ = w.Write([]byte("\xEF\xBB\xBF"))
+		rw = &csvRowWriter{w: csv.NewWriter(w)}
+	case ExportXLSX:
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		rw = newXLSXWriter(w, label)
+	}
+
+	fields := b.visibleFields(r)
+	var headers []interface{}
+	for _, f := range fields {
+		headers = append(headers, i18n.PT(r, ModelsI18nModuleKey, b.mb.label, b.mb.getLabel(f.NameLabel)))
+	}
+	err := rw.Write(headers)
+	if err != nil {
+		panic(err)
+	}
+
+	// fetch in batches, so that rows are streamed and not all loaded into memory at once
+	for searchParams.Page = 1; ; searchParams.Page++ {
+		objs, _, err := b.searcher(b.mb.newModelArray(), searchParams, ctx)
+		if err != nil {
+			panic(err)
+		}
+
+		rv := reflect.ValueOf(objs)
+		for i := 0; i < rv.Len(); i++ {
+			obj := rv.Index(i).Interface()
+			var cells []interface{}
+			for _, f := range fields {
+				var v interface{}
+				if f.exportFunc != nil {
+					v = f.exportFunc(obj, b.mb.getComponentFuncField(f), ctx)
+				} else {
+					v = exportValue(obj, f.name)
+				}
+				cells = append(cells, escapeFormula(v))
+			}
+			err = rw.Write(cells)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		if int64(rv.Len()) < searchParams.PerPage {
+			break
+		}
+	}
+
+	err = rw.Close()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (b *ListingBuilder) canExport(format ExportFormat) bool {
+	for _, f := range b.exportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// exportValue returns the field value of obj for a spreadsheet cell,
+// fields that are not on the struct like custom listing columns are left empty
+func exportValue(obj interface{}, fieldName string) (r interface{}) {
+	val := obj
+	if len(fieldName) > 0 {
+		var err error
+		val, err = reflectutils.Get(obj, fieldName)
+		if err != nil {
+			return ""
+		}
+	}
+	if val == nil {
+		return ""
+	}
+
+	switch vt := val.(type) {
+	case []rune:
+		return string(vt)
+	case []byte:
+		return string(vt)
+	case time.Time:
+		return vt.Format("2006-01-02 15:04:05")
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return ""
+	}
+
+	if s, ok := val.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	if rv.Kind() == reflect.Ptr {
+		return exportValue(rv.Elem().Interface(), "")
+	}
+	return val
+}
+
+// escapeFormula prefixes the text cells that spreadsheet apps would evaluate as formulas with ',
+// that the exported data can't inject formulas
+func escapeFormula(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok || len(s) == 0 {
+		return v
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return v
+}
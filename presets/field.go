@@ -17,6 +17,7 @@ type FieldBuilder struct {
 	NameLabel
 	compFunc   FieldComponentFunc
 	setterFunc FieldSetterFunc
+	exportFunc FieldExportFunc
 	context    context.Context
 }
 
@@ -43,6 +44,8 @@ func (b *FieldBuilder) Clone() (r *FieldBuilder) {
 	r.label = b.label
 	r.compFunc = b.compFunc
 	r.setterFunc = b.setterFunc
+	r.exportFunc = b.exportFunc
+	r.context = b.context
 	return r
 }
 
@@ -59,6 +62,13 @@ func (b *FieldBuilder) SetterFunc(v FieldSetterFunc) (r *FieldBuilder) {
 	return b
 }
 
+// ExportFunc formats the cells of the field in the exported CSV and XLSX files,
+// the ComponentFunc is not used by the exports that the field value is exported by default
+func (b *FieldBuilder) ExportFunc(v FieldExportFunc) (r *FieldBuilder) {
+	b.exportFunc = v
+	return b
+}
+
 func (b *FieldBuilder) WithContextValue(key interface{}, val interface{}) (r *FieldBuilder) {
 	if b.context == nil {
 		b.context = context.Background()
@@ -206,10 +216,15 @@ func (b *FieldBuilders) ToComponent(mb *ModelBuilder, obj interface{}, verr *web
 			continue
 		}
 
+		label := i18n.PT(ctx.R, ModelsI18nModuleKey, mb.label, b.getLabel(f.NameLabel))
+		if isRequired(mb.model, f.name) {
+			label += " *"
+		}
+
 		comps = append(comps, f.compFunc(obj, &FieldContext{
 			ModelInfo: mb.Info(),
 			Name:      f.name,
-			Label:     i18n.PT(ctx.R, ModelsI18nModuleKey, mb.label, b.getLabel(f.NameLabel)),
+			Label:     label,
 			Errors:    verr.GetFieldErrors(f.name),
 			Context:   f.context,
 		}, ctx))
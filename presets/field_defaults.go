@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"time"
 
 	"github.com/goplaid/web"
 	"github.com/goplaid/x/presets/actions"
@@ -86,6 +87,11 @@ var stringVals = []interface{}{
 	[]byte(""),
 }
 
+var timeVals = []interface{}{
+	time.Time{},
+	&time.Time{},
+}
+
 type FieldDefaults struct {
 	mode             FieldMode
 	fieldTypes       []*FieldDefaultBuilder
@@ -167,6 +173,9 @@ func (b *FieldDefaults) fieldTypeByType(tv reflect.Type) (r *FieldDefaultBuilder
 			return ft
 		}
 	}
+	if isSelectable(tv) {
+		return b.selectFieldType(tv)
+	}
 	return nil
 }
 
@@ -249,13 +258,24 @@ func (b *FieldDefaults) builtInFieldTypes() {
 
 		for _, v := range numberVals {
 			b.FieldType(v).
-				ComponentFunc(cfTextTd)
+				ComponentFunc(belongsToOr(LIST, cfTextTd))
 		}
 
 		for _, v := range stringVals {
 			b.FieldType(v).
 				ComponentFunc(cfTextTd)
 		}
+
+		b.FieldType(Attachment{}).
+			ComponentFunc(cfAttachmentTd)
+
+		b.FieldType(RichText("")).
+			ComponentFunc(cfRichTextTd)
+
+		for _, v := range timeVals {
+			b.FieldType(v).
+				ComponentFunc(cfTimeTd)
+		}
 		return
 	}
 
@@ -264,7 +284,7 @@ func (b *FieldDefaults) builtInFieldTypes() {
 
 	for _, v := range numberVals {
 		b.FieldType(v).
-			ComponentFunc(cfNumber)
+			ComponentFunc(belongsToOr(b.mode, cfNumber))
 	}
 
 	for _, v := range stringVals {
@@ -272,6 +292,33 @@ func (b *FieldDefaults) builtInFieldTypes() {
 			ComponentFunc(cfTextField)
 	}
 
+	if b.mode == DETAIL {
+		b.FieldType(Attachment{}).
+			ComponentFunc(cfAttachmentDetail)
+
+		b.FieldType(RichText("")).
+			ComponentFunc(cfRichTextDetail)
+
+		for _, v := range timeVals {
+			b.FieldType(v).
+				ComponentFunc(cfTimeDetail)
+		}
+	} else {
+		b.FieldType(Attachment{}).
+			ComponentFunc(cfAttachmentInput).
+			SetterFunc(attachmentSetter)
+
+		b.FieldType(RichText("")).
+			ComponentFunc(cfRichTextEditor).
+			SetterFunc(richTextSetter)
+
+		for _, v := range timeVals {
+			b.FieldType(v).
+				ComponentFunc(cfTimePicker).
+				SetterFunc(timeSetter)
+		}
+	}
+
 	b.Exclude("ID")
 	return
 }
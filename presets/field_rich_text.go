@@ -0,0 +1,107 @@
+package presets
+
+import (
+	"html"
+	"reflect"
+	"strings"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/tiptap"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// RichText is the field type of the html edited with the tiptap editor, the html is sanitized by
+// the RichTextSanitizer of the builder when it's saved and shown
+type RichText string
+
+func (RichText) GormDataType() string {
+	return "text"
+}
+
+var richTextType = reflect.TypeOf(RichText(""))
+
+// HTMLSanitizer removes the elements and attributes that are not allowed from the html,
+// *bluemonday.Policy is one
+type HTMLSanitizer interface {
+	Sanitize(s string) string
+}
+
+// RichTextSanitizer is bluemonday.UGCPolicy() by default, that allows the formatting of the user generated contents
+func (b *Builder) RichTextSanitizer(v HTMLSanitizer) (r *Builder) {
+	b.richTextSanitizer = v
+	return b
+}
+
+var defaultRichTextSanitizer = bluemonday.UGCPolicy()
+
+func (b *Builder) sanitizeRichText(v string) string {
+	if b.richTextSanitizer == nil {
+		return defaultRichTextSanitizer.Sanitize(v)
+	}
+	return b.richTextSanitizer.Sanitize(v)
+}
+
+// registerRichTextAssets adds the tiptap js and css to the layout if any model has the RichText fields,
+// the paths are the same as the ones in the docs, that they are not added twice
+func (b *Builder) registerRichTextAssets() {
+	for _, m := range b.models {
+		if m.hasFieldOfType(richTextType) {
+			b.ExtraAsset("/tiptap.js", "text/javascript", tiptap.JSComponentsPack())
+			b.ExtraAsset("/tiptap.css", "text/css", tiptap.CSSComponentsPack())
+			return
+		}
+	}
+}
+
+const richTextExcerptLength = 100
+
+var richTextStripper = bluemonday.StrictPolicy()
+
+// richTextExcerpt is the plain text of the html with the spaces collapsed, truncated to richTextExcerptLength
+func richTextExcerpt(v string) string {
+	text := strings.Join(strings.Fields(html.UnescapeString(richTextStripper.Sanitize(
+		// the blocks are separated by spaces that the words of them are not joined
+		strings.ReplaceAll(v, "<", " <"),
+	))), " ")
+	runes := []rune(text)
+	if len(runes) <= richTextExcerptLength {
+		return text
+	}
+	return string(runes[:richTextExcerptLength]) + "…"
+}
+
+func cfRichTextTd(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return h.Td(h.Text(richTextExcerpt(field.StringValue(obj))))
+}
+
+func cfRichTextDetail(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return h.Div(
+		h.Div(h.Text(field.Label)).Class("text-caption grey--text"),
+		h.Div(h.RawHTML(field.ModelInfo.p.sanitizeRichText(field.StringValue(obj)))),
+	).Class("mb-4")
+}
+
+func cfRichTextEditor(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	var errs h.HTMLComponent
+	if len(field.Errors) > 0 {
+		errs = h.Div(h.Text(field.Errors[0])).Class("error--text text-caption")
+	}
+	return h.Div(
+		h.Div(h.Text(field.Label)).Class("text-caption grey--text"),
+		tiptap.TipTapEditor().
+			FieldName(field.Name).
+			Value(field.StringValue(obj)),
+		errs,
+	).Class("mb-4")
+}
+
+// richTextSetter sanitizes the html of the editor, the field isn't changed if it's not in the form
+func richTextSetter(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error) {
+	vs, ok := ctx.R.Form[field.Name]
+	if !ok || len(vs) == 0 {
+		return
+	}
+	return reflectutils.Set(obj, field.Name, RichText(field.ModelInfo.p.sanitizeRichText(vs[0])))
+}
@@ -0,0 +1,111 @@
+package presets
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/i18n"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+type SelectItem struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// Selectable is the field type like string enums that has the options, the fields of it are selected in the forms,
+// and shown as the texts of the options, the texts are translated with the models i18n module like the labels
+type Selectable interface {
+	Options(ctx *web.EventContext) []SelectItem
+}
+
+var selectableType = reflect.TypeOf((*Selectable)(nil)).Elem()
+
+func isSelectable(t reflect.Type) bool {
+	return t.Kind() != reflect.Ptr && (t.Implements(selectableType) || reflect.PtrTo(t).Implements(selectableType))
+}
+
+// selectFieldType is the default of the selectable types, that is added when the type is first looked up
+func (b *FieldDefaults) selectFieldType(t reflect.Type) (r *FieldDefaultBuilder) {
+	r = NewFieldDefault(t)
+	switch b.mode {
+	case LIST:
+		r.ComponentFunc(cfSelectTd)
+	case DETAIL:
+		r.ComponentFunc(cfSelectDetail)
+	default:
+		r.ComponentFunc(cfSelect).SetterFunc(selectSetter)
+	}
+	b.fieldTypes = append(b.fieldTypes, r)
+	return
+}
+
+// selectOptions are the options of the field with the translated texts
+func selectOptions(obj interface{}, field *FieldContext, ctx *web.EventContext) (r []SelectItem) {
+	t := reflectutils.GetType(obj, field.Name)
+	s := reflect.New(t).Interface().(Selectable)
+	for _, item := range s.Options(ctx) {
+		item.Text = i18n.PT(ctx.R, ModelsI18nModuleKey, field.ModelInfo.label, item.Text)
+		r = append(r, item)
+	}
+	return
+}
+
+// selectText is the text of the option of the field, or the value if it's not one of the options
+func selectText(obj interface{}, field *FieldContext, ctx *web.EventContext) string {
+	value := field.StringValue(obj)
+	for _, item := range selectOptions(obj, field, ctx) {
+		if item.Value == value {
+			return item.Text
+		}
+	}
+	return value
+}
+
+func cfSelectTd(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return h.Td(h.Text(selectText(obj, field, ctx)))
+}
+
+func cfSelectDetail(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return h.Div(
+		h.Div(h.Text(field.Label)).Class("text-caption grey--text"),
+		h.Div(h.Text(selectText(obj, field, ctx))),
+	).Class("mb-4")
+}
+
+func cfSelect(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return VSelect().
+		FieldName(field.Name).
+		Label(field.Label).
+		Items(selectOptions(obj, field, ctx)).
+		ItemText("text").
+		ItemValue("value").
+		Value(field.StringValue(obj)).
+		ErrorMessages(field.Errors...)
+}
+
+// selectSetter sets the value that is one of the options or empty, the field isn't changed if it's not in the form
+func selectSetter(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error) {
+	vs, ok := ctx.R.Form[field.Name]
+	if !ok || len(vs) == 0 {
+		return
+	}
+
+	value := vs[0]
+	if len(value) == 0 {
+		return reflectutils.Set(obj, field.Name, reflect.Zero(reflectutils.GetType(obj, field.Name)).Interface())
+	}
+
+	var texts []string
+	for _, item := range selectOptions(obj, field, ctx) {
+		if item.Value == value {
+			return reflectutils.Set(obj, field.Name, value)
+		}
+		texts = append(texts, item.Text)
+	}
+	return errors.New(MustGetMessages(ctx.R).ValidateOneOf(strings.Join(texts, ", ")))
+}
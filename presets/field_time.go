@@ -0,0 +1,181 @@
+package presets
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goplaid/web"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+// TimeKind is how the time.Time and *time.Time fields are shown and edited, they are date times by default
+type TimeKind int
+
+const (
+	TimeKindDateTime TimeKind = iota
+	TimeKindDate
+	TimeKindTime
+)
+
+type timeKindKey struct{}
+
+// TimeKind shows and edits the time.Time or *time.Time field as a date or a time of the day
+func (b *FieldBuilder) TimeKind(v TimeKind) (r *FieldBuilder) {
+	return b.WithContextValue(timeKindKey{}, v)
+}
+
+func (k TimeKind) layout() string {
+	switch k {
+	case TimeKindDate:
+		return "2006-01-02"
+	case TimeKindTime:
+		return "15:04"
+	}
+	return "2006-01-02 15:04"
+}
+
+// TimeLocationFunc is the time zone of the user, that the time fields are shown and parsed in
+type TimeLocationFunc func(r *http.Request) *time.Location
+
+// TimeLocationFunc is time.Local by default
+func (b *Builder) TimeLocationFunc(v TimeLocationFunc) (r *Builder) {
+	b.timeLocationFunc = v
+	return b
+}
+
+func (b *Builder) timeLocation(r *http.Request) *time.Location {
+	if b.timeLocationFunc == nil {
+		return time.Local
+	}
+	if loc := b.timeLocationFunc(r); loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+func fieldTimeKind(field *FieldContext) TimeKind {
+	k, _ := field.ContextValue(timeKindKey{}).(TimeKind)
+	return k
+}
+
+// formatTimeField formats the field in the time zone of the user, it's empty for the zero time and nil
+func formatTimeField(obj interface{}, field *FieldContext, ctx *web.EventContext) string {
+	t, ok := timeValue(obj, field.Name)
+	if !ok {
+		return ""
+	}
+	return t.In(field.ModelInfo.p.timeLocation(ctx.R)).Format(fieldTimeKind(field).layout())
+}
+
+func cfTimeTd(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return h.Td(h.Text(formatTimeField(obj, field, ctx)))
+}
+
+func cfTimeDetail(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	return h.Div(
+		h.Div(h.Text(field.Label)).Class("text-caption grey--text"),
+		h.Div(h.Text(formatTimeField(obj, field, ctx))),
+	).Class("mb-4")
+}
+
+// cfTimePicker is a text field that opens the date or time pickers, the value is kept in the locals of the form,
+// and set to the field only when it's picked or cleared, that the field isn't changed if it's not touched
+func cfTimePicker(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	kind := fieldTimeKind(field)
+	_, isPtr := reflectutils.MustGet(obj, field.Name).(*time.Time)
+
+	valueKey := "timeField_" + field.Name
+	menuKey := "timeFieldMenu_" + field.Name
+	value := fmt.Sprintf("locals[%s]", h.JSONString(valueKey))
+	menu := fmt.Sprintf("locals[%s]", h.JSONString(menuKey))
+	setValue := func(expr string) string {
+		return fmt.Sprintf("%s = %s; $plaid().fieldValue(%s, %s)", value, expr, h.JSONString(timeFieldName(field.Name)), value)
+	}
+
+	icon := "event"
+	var picker h.HTMLComponent
+	switch kind {
+	case TimeKindDate:
+		picker = VDatePicker().NoTitle(true).
+			Attr(":value", value).
+			Attr("@input", setValue("$event")+"; "+menu+" = false")
+	case TimeKindTime:
+		icon = "schedule"
+		picker = VTimePicker().Format("24hr").
+			Attr(":value", value).
+			Attr("@input", setValue("$event")).
+			Attr("@click:minute", menu+" = false")
+	default:
+		picker = h.Div(
+			VDatePicker().NoTitle(true).
+				Attr(":value", fmt.Sprintf("(%s || '').slice(0, 10)", value)).
+				Attr("@input", setValue(fmt.Sprintf("$event + ' ' + ((%s || '').slice(11) || '00:00')", value))),
+			// the time is picked after the date
+			VTimePicker().Format("24hr").
+				Attr(":value", fmt.Sprintf("(%s || '').slice(11)", value)).
+				Attr(":disabled", "!"+value).
+				Attr("@input", setValue(fmt.Sprintf("%s.slice(0, 10) + ' ' + $event", value))),
+		).Class("d-flex")
+	}
+
+	return VMenu(
+		web.Slot(
+			VTextField().
+				Label(field.Label).
+				Readonly(true).
+				PrependIcon(icon).
+				Clearable(isPtr).
+				ErrorMessages(field.Errors...).
+				Attr(":value", value).
+				Attr("@click:clear", setValue("''")).
+				Attr("v-bind", "attrs").
+				Attr("v-on", "on"),
+		).Name("activator").Scope("{ on, attrs }"),
+		picker,
+	).CloseOnContentClick(false).
+		OffsetY(true).
+		Attr("v-model", menu).
+		Attr(web.InitContextLocals, h.JSONString(map[string]interface{}{
+			valueKey: formatTimeField(obj, field, ctx),
+			menuKey:  false,
+		}))
+}
+
+// timeFieldName is the form field of the picked time, the form of the field itself is decoded as RFC 3339
+func timeFieldName(fieldName string) string {
+	return "__Time_" + fieldName
+}
+
+// timeSetter parses the field in the time zone of the user, the empty value is nil for *time.Time,
+// the field isn't changed if it's not in the form, and the date of the field is kept for TimeKindTime
+func timeSetter(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error) {
+	vs, ok := ctx.R.Form[timeFieldName(field.Name)]
+	if !ok || len(vs) == 0 {
+		return
+	}
+
+	v := strings.TrimSpace(vs[0])
+	if len(v) == 0 {
+		if _, isPtr := reflectutils.MustGet(obj, field.Name).(*time.Time); isPtr {
+			return reflectutils.Set(obj, field.Name, nil)
+		}
+		return reflectutils.Set(obj, field.Name, time.Time{})
+	}
+
+	kind := fieldTimeKind(field)
+	loc := field.ModelInfo.p.timeLocation(ctx.R)
+	t, err := time.ParseInLocation(kind.layout(), v, loc)
+	if err != nil {
+		return errors.New(MustGetMessages(ctx.R).ValidateTime)
+	}
+	if old, ok := timeValue(obj, field.Name); ok && kind == TimeKindTime {
+		old = old.In(loc)
+		t = time.Date(old.Year(), old.Month(), old.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	}
+	return setTimeValue(obj, field.Name, t)
+}
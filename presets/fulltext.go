@@ -0,0 +1,246 @@
+package presets
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	h "github.com/theplant/htmlgo"
+)
+
+// FullTextSearch replaces the LIKE keyword search of the data operators with the full-text index of the database
+type FullTextSearch interface {
+	// Condition filters the records of the table matching the keyword in the columns
+	Condition(table string, columns []string, keyword string) *SQLCondition
+	// Rank is the order by clause that sorts the records matching the keyword better first
+	Rank(table string, columns []string, keyword string) *SQLCondition
+	// Setup returns the sql statements that create the index of the columns, and keep it updated
+	Setup(table string, columns []string) []string
+	// Teardown returns the sql statements that remove what Setup creates
+	Teardown(table string) []string
+}
+
+// FullTextSearch searches the keyword with the full-text index instead of LIKE for the search columns,
+// and sorts the results by relevance when the order is not chosen in url
+func (b *ListingBuilder) FullTextSearch(v FullTextSearch) (r *ListingBuilder) {
+	b.fullTextSearch = v
+	return b
+}
+
+// HighlightKeyword shows the text around the keyword in the cells of the fields, with the keyword highlighted
+func (b *ListingBuilder) HighlightKeyword(fieldNames ...string) (r *ListingBuilder) {
+	b.highlightFields = fieldNames
+	return b
+}
+
+func (b *ListingBuilder) isHighlighted(fieldName string) bool {
+	for _, f := range b.highlightFields {
+		if f == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+type PostgresFullTextBuilder struct {
+	config string
+	column string
+}
+
+// PostgresFullText matches the keyword with plainto_tsquery of the text search config like english or simple,
+// and ranks with ts_rank
+func PostgresFullText(config string) (r *PostgresFullTextBuilder) {
+	r = &PostgresFullTextBuilder{config: config}
+	return
+}
+
+// Column uses a tsvector column maintained by the application instead of the expression index created by Setup
+func (b *PostgresFullTextBuilder) Column(v string) (r *PostgresFullTextBuilder) {
+	b.column = v
+	return b
+}
+
+func (b *PostgresFullTextBuilder) vector(table string, columns []string) string {
+	if len(b.column) > 0 {
+		return fmt.Sprintf("%s.%s", table, b.column)
+	}
+
+	var segs []string
+	for _, c := range columns {
+		segs = append(segs, fmt.Sprintf("coalesce(%s.%s, '')", table, c))
+	}
+	return fmt.Sprintf("to_tsvector('%s', %s)", b.config, strings.Join(segs, " || ' ' || "))
+}
+
+func (b *PostgresFullTextBuilder) Condition(table string, columns []string, keyword string) *SQLCondition {
+	return &SQLCondition{
+		Query: fmt.Sprintf("%s @@ plainto_tsquery('%s', ?)", b.vector(table, columns), b.config),
+		Args:  []interface{}{keyword},
+	}
+}
+
+func (b *PostgresFullTextBuilder) Rank(table string, columns []string, keyword string) *SQLCondition {
+	return &SQLCondition{
+		Query: fmt.Sprintf("ts_rank(%s, plainto_tsquery('%s', ?)) DESC", b.vector(table, columns), b.config),
+		Args:  []interface{}{keyword},
+	}
+}
+
+// Setup creates the gin index of the same expression in Condition, that postgres uses it for the search
+func (b *PostgresFullTextBuilder) Setup(table string, columns []string) []string {
+	if len(b.column) > 0 {
+		return []string{
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s USING GIN (%s)", table, b.column, table, b.column),
+		}
+	}
+	// the vector of the index can't have the table name
+	var segs []string
+	for _, c := range columns {
+		segs = append(segs, fmt.Sprintf("coalesce(%s, '')", c))
+	}
+	return []string{
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_fts_idx ON %s USING GIN (to_tsvector('%s', %s))",
+			table, table, b.config, strings.Join(segs, " || ' ' || ")),
+	}
+}
+
+func (b *PostgresFullTextBuilder) Teardown(table string) []string {
+	if len(b.column) > 0 {
+		return []string{fmt.Sprintf("DROP INDEX IF EXISTS %s_%s_idx", table, b.column)}
+	}
+	return []string{fmt.Sprintf("DROP INDEX IF EXISTS %s_fts_idx", table)}
+}
+
+type SQLiteFullTextBuilder struct {
+	primaryColumn string
+}
+
+// SQLiteFullText searches the fts5 table named like {table}_fts, that Setup creates with the triggers to sync it,
+// sqlite must be built with fts5 like the sqlite_fts5 build tag of github.com/mattn/go-sqlite3
+func SQLiteFullText() (r *SQLiteFullTextBuilder) {
+	r = &SQLiteFullTextBuilder{primaryColumn: "id"}
+	return
+}
+
+// PrimaryColumn is the integer primary key of the table that is the rowid of the fts5 table, defaults to id
+func (b *SQLiteFullTextBuilder) PrimaryColumn(v string) (r *SQLiteFullTextBuilder) {
+	b.primaryColumn = v
+	return b
+}
+
+// query quotes all the terms of the keyword, that they are matched together like plainto_tsquery
+func (b *SQLiteFullTextBuilder) query(keyword string) string {
+	var terms []string
+	for _, t := range strings.Fields(keyword) {
+		terms = append(terms, fmt.Sprintf(`"%s"`, strings.Replace(t, `"`, `""`, -1)))
+	}
+	return strings.Join(terms, " ")
+}
+
+func (b *SQLiteFullTextBuilder) Condition(table string, columns []string, keyword string) *SQLCondition {
+	return &SQLCondition{
+		Query: fmt.Sprintf("%s.%s IN (SELECT rowid FROM %s_fts WHERE %s_fts MATCH ?)", table, b.primaryColumn, table, table),
+		Args:  []interface{}{b.query(keyword)},
+	}
+}
+
+func (b *SQLiteFullTextBuilder) Rank(table string, columns []string, keyword string) *SQLCondition {
+	return &SQLCondition{
+		Query: fmt.Sprintf("(SELECT rank FROM %s_fts WHERE %s_fts MATCH ? AND rowid = %s.%s) ASC", table, table, table, b.primaryColumn),
+		Args:  []interface{}{b.query(keyword)},
+	}
+}
+
+// Setup creates the fts5 table with the content of the table, the triggers that sync it, and fills it with the existing records
+func (b *SQLiteFullTextBuilder) Setup(table string, columns []string) []string {
+	fts := fmt.Sprintf("%s_fts", table)
+	values := func(prefix string) string {
+		var vs []string
+		for _, c := range columns {
+			vs = append(vs, fmt.Sprintf("%s.%s", prefix, c))
+		}
+		return strings.Join(vs, ", ")
+	}
+	cols := strings.Join(columns, ", ")
+	insert := fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (new.%s, %s);", fts, cols, b.primaryColumn, values("new"))
+	remove := fmt.Sprintf("INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.%s, %s);", fts, fts, cols, b.primaryColumn, values("old"))
+
+	return []string{
+		fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content='%s', content_rowid='%s')", fts, cols, table, b.primaryColumn),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_ai AFTER INSERT ON %s BEGIN %s END", fts, table, insert),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_ad AFTER DELETE ON %s BEGIN %s END", fts, table, remove),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_au AFTER UPDATE ON %s BEGIN %s %s END", fts, table, remove, insert),
+		fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", fts, fts),
+	}
+}
+
+// Teardown drops the triggers first, that the table can be updated even if the fts5 table can't be dropped
+// by sqlite without fts5
+func (b *SQLiteFullTextBuilder) Teardown(table string) []string {
+	fts := fmt.Sprintf("%s_fts", table)
+	return []string{
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s_ai", fts),
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s_ad", fts),
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s_au", fts),
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", fts),
+	}
+}
+
+// keywordSnippetRadius is the number of characters shown before and after the first match in the snippets
+const keywordSnippetRadius = 40
+
+// keywordSnippet cuts the text around the first term of the keyword found, and marks all the terms in it
+func keywordSnippet(text string, keyword string) h.HTMLComponent {
+	runes := []rune(text)
+	lower := []rune(strings.Map(unicode.ToLower, text))
+
+	var terms [][]rune
+	for _, t := range strings.Fields(keyword) {
+		terms = append(terms, []rune(strings.Map(unicode.ToLower, t)))
+	}
+
+	matchAt := func(i int) int {
+		for _, t := range terms {
+			if i+len(t) <= len(lower) && string(lower[i:i+len(t)]) == string(t) {
+				return len(t)
+			}
+		}
+		return 0
+	}
+
+	start, end := 0, len(runes)
+	for i := range lower {
+		if matchAt(i) > 0 {
+			if i > keywordSnippetRadius {
+				start = i - keywordSnippetRadius
+			}
+			break
+		}
+	}
+	if end-start > 2*keywordSnippetRadius {
+		end = start + 2*keywordSnippetRadius
+	}
+
+	var children []h.HTMLComponent
+	if start > 0 {
+		children = append(children, h.Text("…"))
+	}
+	from := start
+	for i := start; i < end; {
+		n := matchAt(i)
+		if n == 0 {
+			i++
+			continue
+		}
+		children = append(children, h.Text(string(runes[from:i])), h.Tag("mark").Text(string(runes[i:i+n])))
+		i += n
+		from = i
+	}
+	if from < end {
+		children = append(children, h.Text(string(runes[from:end])))
+	}
+	if end < len(runes) {
+		children = append(children, h.Text("…"))
+	}
+	return h.Components(children...)
+}
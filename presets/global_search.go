@@ -0,0 +1,156 @@
+package presets
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/i18n"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	h "github.com/theplant/htmlgo"
+	"go.uber.org/zap"
+)
+
+const globalSearchPath = "/search"
+
+type globalSearchResult struct {
+	mb         *ModelBuilder
+	objs       interface{}
+	totalCount int
+	err        error
+}
+
+// GlobalSearchPerModel is the max number of records shown for every model in the global search page, defaults to 5
+func (b *Builder) GlobalSearchPerModel(v int64) (r *Builder) {
+	b.globalSearchPerModel = v
+	return b
+}
+
+func (b *Builder) globalSearchHref() string {
+	return b.prefix + globalSearchPath
+}
+
+// isListingPath checks if the search box of the app bar can search the records of the current page
+func (b *Builder) isListingPath(path string) bool {
+	for _, m := range b.models {
+		if m.Info().ListingHref() == path {
+			return true
+		}
+	}
+	return false
+}
+
+// globalSearch searches the keyword in all the models that the user can list in parallel
+func (b *Builder) globalSearch(keyword string, ctx *web.EventContext) (r []*globalSearchResult) {
+	perModel := b.globalSearchPerModel
+	if perModel == 0 {
+		perModel = 5
+	}
+
+	for _, m := range b.models {
+		if len(m.listing.searchColumns) == 0 || m.listing.searcher == nil {
+			continue
+		}
+		if m.Info().Verifier().Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+			continue
+		}
+		r = append(r, &globalSearchResult{mb: m})
+	}
+
+	var wg sync.WaitGroup
+	for _, result := range r {
+		wg.Add(1)
+		go func(result *globalSearchResult) {
+			defer wg.Done()
+			lb := result.mb.listing
+			result.objs, result.totalCount, result.err = lb.searcher(result.mb.newModelArray(), &SearchParams{
+				KeywordColumns: lb.searchColumns,
+				Keyword:        keyword,
+				FullTextSearch: lb.fullTextSearch,
+				RankByKeyword:  lb.fullTextSearch != nil,
+				PerPage:        perModel,
+				Page:           1,
+				OrderBy:        lb.defaultOrderBy(),
+			}, ctx)
+		}(result)
+	}
+	wg.Wait()
+	return
+}
+
+func (b *Builder) globalSearchPageFunc(ctx *web.EventContext) (r web.PageResponse, err error) {
+	msgr := MustGetMessages(ctx.R)
+	r.PageTitle = msgr.Search
+
+	keyword := strings.TrimSpace(ctx.R.URL.Query().Get("keyword"))
+	if len(keyword) == 0 {
+		r.Body = VContainer(
+			h.Div(h.Text(msgr.GlobalSearchHint)).Class("grey--text text-center pa-6"),
+		)
+		return
+	}
+
+	var cards []h.HTMLComponent
+	for _, result := range b.globalSearch(keyword, ctx) {
+		// a broken model like the one without its full-text index doesn't stop searching the others
+		if result.err != nil {
+			b.logger.Error("global search", zap.String("model", result.mb.Info().URIName()), zap.Error(result.err))
+			continue
+		}
+		if result.totalCount == 0 {
+			continue
+		}
+		cards = append(cards, b.globalSearchCard(msgr, keyword, result, ctx))
+	}
+
+	if len(cards) == 0 {
+		cards = append(cards, h.Div(h.Text(msgr.GlobalSearchNoResults(keyword))).Class("grey--text text-center pa-6"))
+	}
+
+	r.Body = VContainer(cards...)
+	return
+}
+
+// globalSearchCard lists the records of a model that link to the detail page, or the edit drawer of the model
+func (b *Builder) globalSearchCard(msgr *Messages, keyword string, result *globalSearchResult, ctx *web.EventContext) h.HTMLComponent {
+	mi := result.mb.Info()
+
+	var items []h.HTMLComponent
+	rv := reflect.ValueOf(result.objs)
+	for i := 0; i < rv.Len(); i++ {
+		obj := rv.Index(i).Interface()
+		id := result.mb.objectID(obj)
+
+		var click string
+		if mi.HasDetailing() {
+			click = web.Plaid().PushStateURL(mi.DetailingHref(id)).Go()
+		} else {
+			click = web.Plaid().EventFunc(actions.DrawerEdit, id).URL(mi.ListingHref()).Go()
+		}
+
+		items = append(items, VListItem(
+			VListItemContent(
+				VListItemTitle(h.Text(getPageTitle(obj, id))),
+			),
+		).Attr("@click", click))
+	}
+
+	viewAll := fmt.Sprintf("%s?%s", mi.ListingHref(), url.Values{"keyword": []string{keyword}}.Encode())
+	return VCard(
+		VCardTitle(
+			h.Text(i18n.T(ctx.R, ModelsI18nModuleKey, result.mb.label)),
+			VChip(h.Text(fmt.Sprint(result.totalCount))).Small(true).Class("ml-2"),
+			VSpacer(),
+			VBtn(msgr.GlobalSearchViewAll(result.totalCount)).
+				Text(true).
+				Small(true).
+				Color("primary").
+				Attr("@click", web.Plaid().PushStateURL(viewAll).Go()),
+		).Class("subtitle-1"),
+		VList(items...).Dense(true),
+	).Class("mb-4")
+}
@@ -0,0 +1,61 @@
+package gorm2op
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type listingColumns struct {
+	ID      uint   `gorm:"primarykey"`
+	UserID  string `gorm:"uniqueIndex:idx_listing_columns_user_model"`
+	Model   string `gorm:"uniqueIndex:idx_listing_columns_user_model"`
+	Columns string
+}
+
+func (listingColumns) TableName() string {
+	return "presets_listing_columns"
+}
+
+func ListingColumnsStore(db *gorm.DB) (r *ListingColumnsStoreBuilder) {
+	r = &ListingColumnsStoreBuilder{db: db}
+	return
+}
+
+// ListingColumnsStoreBuilder stores listing columns in the presets_listing_columns table, that is created by AutoMigrate
+type ListingColumnsStoreBuilder struct {
+	db *gorm.DB
+}
+
+func (s *ListingColumnsStoreBuilder) AutoMigrate() (r *ListingColumnsStoreBuilder) {
+	err := s.db.AutoMigrate(&listingColumns{})
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *ListingColumnsStoreBuilder) GetColumns(userID string, model string) (r []string, err error) {
+	var cs listingColumns
+	err = s.db.Where("user_id = ? AND model = ?", userID, model).Limit(1).Find(&cs).Error
+	if err != nil || len(cs.Columns) == 0 {
+		return
+	}
+	r = strings.Split(cs.Columns, ",")
+	return
+}
+
+func (s *ListingColumnsStoreBuilder) SaveColumns(userID string, model string, columns []string) (err error) {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var cs listingColumns
+		err := tx.Where("user_id = ? AND model = ?", userID, model).Limit(1).Find(&cs).Error
+		if err != nil {
+			return err
+		}
+
+		cs.UserID = userID
+		cs.Model = model
+		cs.Columns = strings.Join(columns, ",")
+		return tx.Save(&cs).Error
+	})
+}
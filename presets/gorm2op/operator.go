@@ -8,6 +8,8 @@ import (
 	"github.com/goplaid/web"
 	"github.com/goplaid/x/presets"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 func DataOperator(db *gorm.DB) (r *DataOperatorBuilder) {
@@ -24,24 +26,10 @@ type DataOperatorBuilder struct {
 }
 
 func (op *DataOperatorBuilder) Search(obj interface{}, params *presets.SearchParams, ctx *web.EventContext) (r interface{}, totalCount int, err error) {
-	ilike := "ILIKE"
-	if op.db.Dialector.Name() == "sqlite" {
-		ilike = "LIKE"
-	}
+	wh := op.SearchWhere(obj, params)
 
-	wh := op.db.Model(obj)
-	if len(params.KeywordColumns) > 0 && len(params.Keyword) > 0 {
-		var segs []string
-		var args []interface{}
-		for _, c := range params.KeywordColumns {
-			segs = append(segs, fmt.Sprintf("%s %s ?", c, ilike))
-			args = append(args, fmt.Sprintf("%%%s%%", params.Keyword))
-		}
-		wh = wh.Where(strings.Join(segs, " OR "), args...)
-	}
-
-	for _, cond := range params.SQLConditions {
-		wh = wh.Where(strings.Replace(cond.Query, " ILIKE ", " "+ilike+" ", -1), cond.Args...)
+	if params.Cursor != nil {
+		return op.searchByCursor(wh, obj, params)
 	}
 
 	var c int64
@@ -62,11 +50,21 @@ func (op *DataOperatorBuilder) Search(obj interface{}, params *presets.SearchPar
 	}
 
 	orderBy := params.OrderBy
+	if params.RankByKeyword && params.FullTextSearch != nil && len(params.KeywordColumns) > 0 && len(strings.Fields(params.Keyword)) > 0 {
+		// the rank has args that Order doesn't take, so it's the expression of the whole order by clause
+		rank := params.FullTextSearch.Rank(op.tableName(obj), params.KeywordColumns, params.Keyword)
+		sql := rank.Query
+		if len(orderBy) > 0 {
+			sql = fmt.Sprintf("%s, %s", sql, orderBy)
+		}
+		wh = wh.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: rank.Args}})
+		orderBy = ""
+	}
 	if len(orderBy) > 0 {
 		wh = wh.Order(orderBy)
 	}
 
-	err = wh.Find(obj).Error
+	err = preload(wh, params.Associations).Find(obj).Error
 	if err != nil {
 		return
 	}
@@ -74,6 +72,80 @@ func (op *DataOperatorBuilder) Search(obj interface{}, params *presets.SearchPar
 	return
 }
 
+// SearchWhere returns the query of the keyword and conditions of params without order and pagination,
+// that bulk actions can use to update all the matching records
+func (op *DataOperatorBuilder) SearchWhere(obj interface{}, params *presets.SearchParams) (wh *gorm.DB) {
+	ilike := "ILIKE"
+	if op.db.Dialector.Name() == "sqlite" {
+		ilike = "LIKE"
+	}
+
+	wh = op.db.Model(obj)
+	if params.FullTextSearch != nil && len(params.KeywordColumns) > 0 {
+		// the keyword of only spaces has no terms to match, that full-text searches like sqlite fts5 fail with
+		if len(strings.Fields(params.Keyword)) > 0 {
+			cond := params.FullTextSearch.Condition(op.tableName(obj), params.KeywordColumns, params.Keyword)
+			wh = wh.Where(cond.Query, cond.Args...)
+		}
+	} else if len(params.KeywordColumns) > 0 && len(params.Keyword) > 0 {
+		var segs []string
+		var args []interface{}
+		for _, c := range params.KeywordColumns {
+			segs = append(segs, fmt.Sprintf("%s %s ?", c, ilike))
+			args = append(args, fmt.Sprintf("%%%s%%", params.Keyword))
+		}
+		wh = wh.Where(strings.Join(segs, " OR "), args...)
+	}
+
+	for _, cond := range params.SQLConditions {
+		wh = wh.Where(strings.Replace(cond.Query, " ILIKE ", " "+ilike+" ", -1), cond.Args...)
+	}
+	return
+}
+
+// searchByCursor skips counting that is slow for huge tables, and seeks by the order columns instead of offset
+func (op *DataOperatorBuilder) searchByCursor(wh *gorm.DB, obj interface{}, params *presets.SearchParams) (r interface{}, totalCount int, err error) {
+	if cond := params.Cursor.SQLCondition(); cond != nil {
+		wh = wh.Where(cond.Query, cond.Args...)
+	}
+	if params.PerPage > 0 {
+		wh = wh.Limit(int(params.PerPage))
+	}
+
+	err = preload(wh, params.Associations).Order(params.Cursor.OrderBy()).Find(obj).Error
+	if err != nil {
+		return
+	}
+	r = params.Cursor.Records(reflect.ValueOf(obj).Elem().Interface())
+	return
+}
+
+// Aggregate computes the aggregates over the records matching the search params in one query,
+// results are ordered by the group by column
+func (op *DataOperatorBuilder) Aggregate(obj interface{}, params *presets.SearchParams, aggParams *presets.AggregateParams, ctx *web.EventContext) (r []*presets.AggregateResult, err error) {
+	wh := op.SearchWhere(obj, params)
+	if len(aggParams.GroupBy) > 0 {
+		wh = wh.Group(aggParams.GroupBy).Order(aggParams.GroupBy)
+	}
+
+	rows, err := wh.Select(aggParams.SelectSQL()).Rows()
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	return aggParams.ScanResults(rows)
+}
+
+// tableName works for the models and the slices of models
+func (op *DataOperatorBuilder) tableName(obj interface{}) string {
+	stmt := &gorm.Statement{DB: op.db}
+	err := stmt.Parse(obj)
+	if err != nil {
+		panic(err)
+	}
+	return stmt.Schema.Table
+}
+
 func (op *DataOperatorBuilder) primarySluggerWhere(obj interface{}, id string) *gorm.DB {
 	wh := op.db.Model(obj)
 
@@ -94,7 +166,12 @@ func (op *DataOperatorBuilder) primarySluggerWhere(obj interface{}, id string) *
 }
 
 func (op *DataOperatorBuilder) Fetch(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error) {
-	err = op.primarySluggerWhere(obj, id).First(obj).Error
+	return op.FetchWithParams(obj, id, &presets.FetchParams{}, ctx)
+}
+
+// FetchWithParams fetches like Fetch, and loads the has-many and many-to-many fields of params.Associations
+func (op *DataOperatorBuilder) FetchWithParams(obj interface{}, id string, params *presets.FetchParams, ctx *web.EventContext) (r interface{}, err error) {
+	err = preload(op.primarySluggerWhere(obj, id), params.Associations).First(obj).Error
 	if err != nil {
 		return
 	}
@@ -102,15 +179,217 @@ func (op *DataOperatorBuilder) Fetch(obj interface{}, id string, ctx *web.EventC
 	return
 }
 
+// Save saves the object, the has-many and many-to-many fields are saved by gorm, and the removed ones are not deleted
 func (op *DataOperatorBuilder) Save(obj interface{}, id string, ctx *web.EventContext) (err error) {
-	if len(id) == 0 {
-		err = op.db.Create(obj).Error
+	return op.save(obj, id, nil)
+}
+
+// SaveWithParams saves like Save in one transaction, updates params.Fields even if they are set to zero values,
+// and saves only the has-many and many-to-many fields of params.Associations
+func (op *DataOperatorBuilder) SaveWithParams(obj interface{}, id string, params *presets.SaveParams, ctx *web.EventContext) (err error) {
+	return op.save(obj, id, params)
+}
+
+// save leaves the has-many and many-to-many fields to gorm if params is nil
+func (op *DataOperatorBuilder) save(obj interface{}, id string, params *presets.SaveParams) (err error) {
+	var omits []string
+	if params != nil {
+		rels := op.relationships(obj)
+		for _, rel := range append(append([]*schema.Relationship{}, rels.HasMany...), rels.Many2Many...) {
+			omits = append(omits, rel.Name)
+		}
+	} else {
+		params = &presets.SaveParams{}
+	}
+
+	return op.db.Transaction(func(tx *gorm.DB) (err error) {
+		txOp := &DataOperatorBuilder{db: tx}
+		if len(id) == 0 {
+			err = tx.Omit(omits...).Create(obj).Error
+			if err != nil {
+				return
+			}
+			return txOp.saveAssociations(obj, params.Associations)
+		}
+
+		wh := txOp.primarySluggerWhere(obj, id)
+		if params.LockVersion != nil {
+			var column string
+			column, err = txOp.columnName(obj, params.LockVersion.Field)
+			if err != nil {
+				return
+			}
+			cond := params.LockVersion.SQLCondition(column)
+			wh = wh.Where(cond.Query, cond.Args...)
+		}
+		if len(params.Fields) > 0 {
+			var columns []string
+			columns, err = txOp.updateColumns(obj, params.Fields)
+			if err != nil {
+				return
+			}
+			wh = wh.Select(columns)
+		}
+		result := wh.Omit(omits...).Updates(obj)
+		if result.Error != nil {
+			return result.Error
+		}
+		if params.LockVersion != nil && result.RowsAffected == 0 {
+			return presets.ErrLockConflict
+		}
+		return txOp.saveAssociations(obj, params.Associations)
+	})
+}
+
+// relationships works for the models and the slices of models
+func (op *DataOperatorBuilder) relationships(obj interface{}) schema.Relationships {
+	stmt := &gorm.Statement{DB: op.db}
+	err := stmt.Parse(obj)
+	if err != nil {
+		panic(err)
+	}
+	return stmt.Schema.Relationships
+}
+
+// preload loads the associated records of the fields
+func preload(wh *gorm.DB, associations []string) *gorm.DB {
+	for _, name := range associations {
+		wh = wh.Preload(name)
+	}
+	return wh
+}
+
+// saveAssociations saves the children of the has-many fields and the associations of the many-to-many fields of associations
+func (op *DataOperatorBuilder) saveAssociations(obj interface{}, associations []string) (err error) {
+	err = op.saveHasMany(obj, associations)
+	if err != nil {
 		return
 	}
-	err = op.primarySluggerWhere(obj, id).Updates(obj).Error
+	for _, rel := range op.relationships(obj).Many2Many {
+		if !hasString(associations, rel.Name) {
+			continue
+		}
+		err = op.ReplaceAssociation(obj, rel.Name)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
+// ReplaceAssociation replaces the associated records of the many-to-many field of obj like Tags with the records in it,
+// that the rows of the join table are inserted or deleted, the field is skipped if it's a nil slice that it's not loaded.
+// the associated records are not updated, only their primary keys are required
+func (op *DataOperatorBuilder) ReplaceAssociation(obj interface{}, fieldName string) (err error) {
+	field := reflect.Indirect(reflect.ValueOf(obj)).FieldByName(fieldName)
+	if field.IsNil() {
+		return
+	}
+	if field.Len() == 0 {
+		return op.db.Model(obj).Association(fieldName).Clear()
+	}
+	return op.db.Model(obj).Association(fieldName).Replace(field.Interface())
+}
+
+// saveHasMany saves the children of the has-many fields of associations with the foreign keys of obj, and deletes
+// the children that are removed from the fields, the fields of nil slices are skipped that they are not loaded
+func (op *DataOperatorBuilder) saveHasMany(obj interface{}, associations []string) (err error) {
+	rv := reflect.ValueOf(obj)
+	for _, rel := range op.relationships(obj).HasMany {
+		if !hasString(associations, rel.Name) {
+			continue
+		}
+		children := rel.Field.ReflectValueOf(rv)
+		if children.IsNil() {
+			continue
+		}
+
+		var ids []interface{}
+		for i := 0; i < children.Len(); i++ {
+			child := children.Index(i)
+			if child.Kind() != reflect.Ptr {
+				child = child.Addr()
+			}
+			for _, ref := range rel.References {
+				v := interface{}(ref.PrimaryValue)
+				if ref.OwnPrimaryKey {
+					v, _ = ref.PrimaryKey.ValueOf(rv)
+				}
+				err = ref.ForeignKey.Set(child, v)
+				if err != nil {
+					return
+				}
+			}
+			err = op.db.Save(child.Interface()).Error
+			if err != nil {
+				return
+			}
+			id, _ := rel.FieldSchema.PrioritizedPrimaryField.ValueOf(child)
+			ids = append(ids, id)
+		}
+
+		wh := op.db
+		for _, ref := range rel.References {
+			v := interface{}(ref.PrimaryValue)
+			if ref.OwnPrimaryKey {
+				v, _ = ref.PrimaryKey.ValueOf(rv)
+			}
+			wh = wh.Where(fmt.Sprintf("%s = ?", ref.ForeignKey.DBName), v)
+		}
+		if len(ids) > 0 {
+			wh = wh.Where(fmt.Sprintf("%s NOT IN ?", rel.FieldSchema.PrioritizedPrimaryField.DBName), ids)
+		}
+		err = wh.Delete(reflect.New(rel.FieldSchema.ModelType).Interface()).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// updateColumns are the columns of the fields that Updates saves, which are the ones with non-zero values, plus fields
+func (op *DataOperatorBuilder) updateColumns(obj interface{}, fields []string) (r []string, err error) {
+	stmt := &gorm.Statement{DB: op.db}
+	err = stmt.Parse(obj)
+	if err != nil {
+		return
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(obj))
+	for _, f := range stmt.Schema.Fields {
+		if len(f.DBName) == 0 {
+			continue
+		}
+		if _, zero := f.ValueOf(rv); !zero || hasString(fields, f.Name) {
+			r = append(r, f.DBName)
+		}
+	}
+	return
+}
+
+func (op *DataOperatorBuilder) columnName(obj interface{}, fieldName string) (r string, err error) {
+	stmt := &gorm.Statement{DB: op.db}
+	err = stmt.Parse(obj)
+	if err != nil {
+		return
+	}
+	f := stmt.Schema.LookUpField(fieldName)
+	if f == nil {
+		err = fmt.Errorf("%s of %T is not a column", fieldName, obj)
+		return
+	}
+	return f.DBName, nil
+}
+
+func hasString(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
 func (op *DataOperatorBuilder) Delete(obj interface{}, id string, ctx *web.EventContext) (err error) {
 	err = op.primarySluggerWhere(obj, id).Delete(obj).Error
 	return
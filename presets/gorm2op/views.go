@@ -0,0 +1,76 @@
+package gorm2op
+
+import (
+	"github.com/goplaid/x/presets"
+	"gorm.io/gorm"
+)
+
+type listingView struct {
+	ID     uint   `gorm:"primarykey"`
+	UserID string `gorm:"uniqueIndex:idx_listing_views_user_model_name"`
+	Model  string `gorm:"uniqueIndex:idx_listing_views_user_model_name"`
+	Name   string `gorm:"uniqueIndex:idx_listing_views_user_model_name"`
+	Query  string
+}
+
+func (listingView) TableName() string {
+	return "presets_listing_views"
+}
+
+func ListingViewStore(db *gorm.DB) (r *ListingViewStoreBuilder) {
+	r = &ListingViewStoreBuilder{db: db}
+	return
+}
+
+// ListingViewStoreBuilder stores listing views in the presets_listing_views table, that is created by AutoMigrate
+type ListingViewStoreBuilder struct {
+	db *gorm.DB
+}
+
+func (s *ListingViewStoreBuilder) AutoMigrate() (r *ListingViewStoreBuilder) {
+	err := s.db.AutoMigrate(&listingView{})
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *ListingViewStoreBuilder) ListViews(userID string, model string) (r []*presets.ListingView, err error) {
+	var vs []*listingView
+	err = s.db.Where("user_id = ? AND model = ?", userID, model).Order("name").Find(&vs).Error
+	if err != nil {
+		return
+	}
+	for _, v := range vs {
+		r = append(r, &presets.ListingView{
+			UserID: v.UserID,
+			Model:  v.Model,
+			Name:   v.Name,
+			Query:  v.Query,
+		})
+	}
+	return
+}
+
+func (s *ListingViewStoreBuilder) SaveView(v *presets.ListingView) (err error) {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var ev listingView
+		err := tx.Where("user_id = ? AND model = ? AND name = ?", v.UserID, v.Model, v.Name).
+			Limit(1).Find(&ev).Error
+		if err != nil {
+			return err
+		}
+
+		ev.UserID = v.UserID
+		ev.Model = v.Model
+		ev.Name = v.Name
+		ev.Query = v.Query
+		return tx.Save(&ev).Error
+	})
+}
+
+func (s *ListingViewStoreBuilder) DeleteView(userID string, model string, name string) (err error) {
+	err = s.db.Where("user_id = ? AND model = ? AND name = ?", userID, model, name).
+		Delete(&listingView{}).Error
+	return
+}
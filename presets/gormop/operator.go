@@ -24,24 +24,10 @@ type DataOperatorBuilder struct {
 }
 
 func (op *DataOperatorBuilder) Search(obj interface{}, params *presets.SearchParams, ctx *web.EventContext) (r interface{}, totalCount int, err error) {
-	ilike := "ILIKE"
-	if op.db.Dialect().GetName() == "sqlite3" {
-		ilike = "LIKE"
-	}
+	wh := op.SearchWhere(obj, params)
 
-	wh := op.db.Model(obj)
-	if len(params.KeywordColumns) > 0 && len(params.Keyword) > 0 {
-		var segs []string
-		var args []interface{}
-		for _, c := range params.KeywordColumns {
-			segs = append(segs, fmt.Sprintf("%s %s ?", c, ilike))
-			args = append(args, fmt.Sprintf("%%%s%%", params.Keyword))
-		}
-		wh = wh.Where(strings.Join(segs, " OR "), args...)
-	}
-
-	for _, cond := range params.SQLConditions {
-		wh = wh.Where(strings.Replace(cond.Query, " ILIKE ", " "+ilike+" ", -1), cond.Args...)
+	if params.Cursor != nil {
+		return op.searchByCursor(wh, obj, params)
 	}
 
 	err = wh.Count(&totalCount).Error
@@ -59,12 +45,17 @@ func (op *DataOperatorBuilder) Search(obj interface{}, params *presets.SearchPar
 		wh = wh.Offset(offset)
 	}
 
+	if params.RankByKeyword && params.FullTextSearch != nil && len(params.KeywordColumns) > 0 && len(strings.Fields(params.Keyword)) > 0 {
+		rank := params.FullTextSearch.Rank(op.db.NewScope(obj).TableName(), params.KeywordColumns, params.Keyword)
+		wh = wh.Order(gorm.Expr(rank.Query, rank.Args...))
+	}
+
 	orderBy := params.OrderBy
 	if len(orderBy) > 0 {
 		wh = wh.Order(orderBy)
 	}
 
-	err = wh.Find(obj).Error
+	err = preload(wh, params.Associations).Find(obj).Error
 	if err != nil {
 		return
 	}
@@ -72,6 +63,70 @@ func (op *DataOperatorBuilder) Search(obj interface{}, params *presets.SearchPar
 	return
 }
 
+// SearchWhere returns the query of the keyword and conditions of params without order and pagination,
+// that bulk actions can use to update all the matching records
+func (op *DataOperatorBuilder) SearchWhere(obj interface{}, params *presets.SearchParams) (wh *gorm.DB) {
+	ilike := "ILIKE"
+	if op.db.Dialect().GetName() == "sqlite3" {
+		ilike = "LIKE"
+	}
+
+	wh = op.db.Model(obj)
+	if params.FullTextSearch != nil && len(params.KeywordColumns) > 0 {
+		// the keyword of only spaces has no terms to match, that full-text searches like sqlite fts5 fail with
+		if len(strings.Fields(params.Keyword)) > 0 {
+			cond := params.FullTextSearch.Condition(op.db.NewScope(obj).TableName(), params.KeywordColumns, params.Keyword)
+			wh = wh.Where(cond.Query, cond.Args...)
+		}
+	} else if len(params.KeywordColumns) > 0 && len(params.Keyword) > 0 {
+		var segs []string
+		var args []interface{}
+		for _, c := range params.KeywordColumns {
+			segs = append(segs, fmt.Sprintf("%s %s ?", c, ilike))
+			args = append(args, fmt.Sprintf("%%%s%%", params.Keyword))
+		}
+		wh = wh.Where(strings.Join(segs, " OR "), args...)
+	}
+
+	for _, cond := range params.SQLConditions {
+		wh = wh.Where(strings.Replace(cond.Query, " ILIKE ", " "+ilike+" ", -1), cond.Args...)
+	}
+	return
+}
+
+// searchByCursor skips counting that is slow for huge tables, and seeks by the order columns instead of offset
+func (op *DataOperatorBuilder) searchByCursor(wh *gorm.DB, obj interface{}, params *presets.SearchParams) (r interface{}, totalCount int, err error) {
+	if cond := params.Cursor.SQLCondition(); cond != nil {
+		wh = wh.Where(cond.Query, cond.Args...)
+	}
+	if params.PerPage > 0 {
+		wh = wh.Limit(params.PerPage)
+	}
+
+	err = preload(wh, params.Associations).Order(params.Cursor.OrderBy()).Find(obj).Error
+	if err != nil {
+		return
+	}
+	r = params.Cursor.Records(reflect.ValueOf(obj).Elem().Interface())
+	return
+}
+
+// Aggregate computes the aggregates over the records matching the search params in one query,
+// results are ordered by the group by column
+func (op *DataOperatorBuilder) Aggregate(obj interface{}, params *presets.SearchParams, aggParams *presets.AggregateParams, ctx *web.EventContext) (r []*presets.AggregateResult, err error) {
+	wh := op.SearchWhere(obj, params)
+	if len(aggParams.GroupBy) > 0 {
+		wh = wh.Group(aggParams.GroupBy).Order(aggParams.GroupBy)
+	}
+
+	rows, err := wh.Select(aggParams.SelectSQL()).Rows()
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	return aggParams.ScanResults(rows)
+}
+
 func (op *DataOperatorBuilder) primarySluggerWhere(obj interface{}, id string) *gorm.DB {
 	wh := op.db.Model(obj)
 
@@ -92,7 +147,12 @@ func (op *DataOperatorBuilder) primarySluggerWhere(obj interface{}, id string) *
 }
 
 func (op *DataOperatorBuilder) Fetch(obj interface{}, id string, ctx *web.EventContext) (r interface{}, err error) {
-	err = op.primarySluggerWhere(obj, id).Find(obj).Error
+	return op.FetchWithParams(obj, id, &presets.FetchParams{}, ctx)
+}
+
+// FetchWithParams fetches like Fetch, and loads the has-many and many-to-many fields of params.Associations
+func (op *DataOperatorBuilder) FetchWithParams(obj interface{}, id string, params *presets.FetchParams, ctx *web.EventContext) (r interface{}, err error) {
+	err = preload(op.primarySluggerWhere(obj, id), params.Associations).Find(obj).Error
 	if err != nil {
 		return
 	}
@@ -100,15 +160,160 @@ func (op *DataOperatorBuilder) Fetch(obj interface{}, id string, ctx *web.EventC
 	return
 }
 
+// Save saves the object, the has-many and many-to-many fields are saved by gorm, and the removed ones are not deleted
 func (op *DataOperatorBuilder) Save(obj interface{}, id string, ctx *web.EventContext) (err error) {
-	if len(id) == 0 {
-		err = op.db.Create(obj).Error
+	return op.save(obj, id, nil)
+}
+
+// SaveWithParams saves like Save in one transaction, updates params.Fields even if they are set to zero values,
+// and saves only the has-many and many-to-many fields of params.Associations
+func (op *DataOperatorBuilder) SaveWithParams(obj interface{}, id string, params *presets.SaveParams, ctx *web.EventContext) (err error) {
+	return op.save(obj, id, params)
+}
+
+// save leaves the has-many and many-to-many fields to gorm if params is nil
+func (op *DataOperatorBuilder) save(obj interface{}, id string, params *presets.SaveParams) (err error) {
+	var omits []string
+	if params != nil {
+		for _, f := range append(op.relationFields(obj, "has_many"), op.relationFields(obj, "many_to_many")...) {
+			if !hasString(params.Associations, f.Name) {
+				omits = append(omits, f.Name)
+			}
+		}
+	} else {
+		params = &presets.SaveParams{}
+	}
+
+	return op.db.Transaction(func(tx *gorm.DB) (err error) {
+		txOp := &DataOperatorBuilder{db: tx}
+		if len(id) == 0 {
+			err = tx.Omit(omits...).Create(obj).Error
+		} else {
+			wh := txOp.primarySluggerWhere(obj, id)
+			if params.LockVersion != nil {
+				f, ok := tx.NewScope(obj).FieldByName(params.LockVersion.Field)
+				if !ok {
+					return fmt.Errorf("%s of %T is not a column", params.LockVersion.Field, obj)
+				}
+				cond := params.LockVersion.SQLCondition(f.DBName)
+				wh = wh.Where(cond.Query, cond.Args...)
+			}
+			result := wh.Omit(omits...).Update(obj)
+			err = result.Error
+			if err == nil && params.LockVersion != nil && result.RowsAffected == 0 {
+				err = presets.ErrLockConflict
+			}
+			if err == nil {
+				err = txOp.updateZeroFields(obj, id, params.Fields)
+			}
+		}
+		if err != nil {
+			return
+		}
+		err = txOp.deleteRemovedHasMany(obj, params.Associations)
+		if err != nil {
+			return
+		}
+		return txOp.replaceMany2Many(obj, params.Associations)
+	})
+}
+
+// updateZeroFields updates the fields that are set to zero values, which Update skips
+func (op *DataOperatorBuilder) updateZeroFields(obj interface{}, id string, fields []string) (err error) {
+	zeros := map[string]interface{}{}
+	for _, f := range op.db.NewScope(obj).Fields() {
+		if f.IsNormal && !f.IsPrimaryKey && f.IsBlank && hasString(fields, f.Name) {
+			zeros[f.DBName] = f.Field.Interface()
+		}
+	}
+	if len(zeros) == 0 {
 		return
 	}
-	err = op.primarySluggerWhere(obj, id).Update(obj).Error
+	err = op.primarySluggerWhere(obj, id).UpdateColumns(zeros).Error
+	return
+}
+
+func (op *DataOperatorBuilder) relationFields(obj interface{}, kind string) (r []*gorm.Field) {
+	for _, f := range op.db.NewScope(obj).Fields() {
+		if f.Relationship != nil && f.Relationship.Kind == kind {
+			r = append(r, f)
+		}
+	}
+	return
+}
+
+// replaceMany2Many deletes the rows of the join tables of the associated records that are removed from the many-to-many
+// fields of associations, gorm saves the others with obj, the fields of nil slices are skipped that they are not loaded
+func (op *DataOperatorBuilder) replaceMany2Many(obj interface{}, associations []string) (err error) {
+	for _, f := range op.relationFields(obj, "many_to_many") {
+		if !hasString(associations, f.Name) || f.Field.IsNil() {
+			continue
+		}
+		err = op.db.Model(obj).Association(f.Name).Replace(f.Field.Interface()).Error
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
+// deleteRemovedHasMany deletes the children that are removed from the has-many fields of associations, gorm saves
+// the others with obj, the fields of nil slices are skipped that they are not loaded
+func (op *DataOperatorBuilder) deleteRemovedHasMany(obj interface{}, associations []string) (err error) {
+	scope := op.db.NewScope(obj)
+	for _, f := range op.relationFields(obj, "has_many") {
+		if !hasString(associations, f.Name) || f.Field.IsNil() {
+			continue
+		}
+
+		rel := f.Relationship
+		wh := op.db
+		for i, fk := range rel.ForeignDBNames {
+			pf, _ := scope.FieldByName(rel.AssociationForeignFieldNames[i])
+			wh = wh.Where(fmt.Sprintf("%s = ?", fk), pf.Field.Interface())
+		}
+		if len(rel.PolymorphicDBName) > 0 {
+			wh = wh.Where(fmt.Sprintf("%s = ?", rel.PolymorphicDBName), rel.PolymorphicValue)
+		}
+
+		elemType := f.Field.Type().Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		child := reflect.New(elemType).Interface()
+
+		var ids []interface{}
+		for i := 0; i < f.Field.Len(); i++ {
+			ids = append(ids, op.db.NewScope(f.Field.Index(i).Interface()).PrimaryKeyValue())
+		}
+		if len(ids) > 0 {
+			wh = wh.Where(fmt.Sprintf("%s NOT IN (?)", op.db.NewScope(child).PrimaryKey()), ids)
+		}
+		err = wh.Delete(child).Error
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// preload loads the associated records of the fields
+func preload(wh *gorm.DB, associations []string) *gorm.DB {
+	for _, name := range associations {
+		wh = wh.Preload(name)
+	}
+	return wh
+}
+
+func hasString(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
 func (op *DataOperatorBuilder) Delete(obj interface{}, id string, ctx *web.EventContext) (err error) {
 	err = op.primarySluggerWhere(obj, id).Delete(obj).Error
 	return
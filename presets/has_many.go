@@ -0,0 +1,305 @@
+package presets
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/i18n"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+type HasManyBuilder struct {
+	eb        *EditingBuilder
+	fieldName string
+	sliceType reflect.Type
+	elemType  reflect.Type
+	sortField string
+	FieldBuilders
+}
+
+// HasMany edits the children of the slice field like CreditCards in the form of the parent, that the rows of them can be
+// added, removed and reordered, and they are saved with the parent by the saver, fields are the fields of the children
+func (b *EditingBuilder) HasMany(fieldName string, fields ...string) (r *HasManyBuilder) {
+	if r = b.hasManyField(fieldName); r != nil {
+		return
+	}
+
+	sliceType := reflectutils.GetType(b.mb.model, fieldName)
+	if sliceType == nil || sliceType.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("%s of %s must be a slice", fieldName, b.mb.label))
+	}
+	elemType := sliceType.Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	structValidateRules(elemType)
+
+	r = &HasManyBuilder{
+		eb:        b,
+		fieldName: fieldName,
+		sliceType: sliceType,
+		elemType:  elemType,
+	}
+	r.FieldBuilders = *b.mb.p.writeFieldDefaults.InspectFields(reflect.New(elemType).Interface())
+	if len(fields) == 0 {
+		// the foreign key is set to the parent when saving
+		r.FieldBuilders = r.FieldBuilders.without(b.mb.modelType.Elem().Name() + "ID")
+	}
+	r.FieldBuilders = *r.FieldBuilders.Only(fields...)
+
+	b.Field(fieldName).ComponentFunc(r.component)
+	b.hasMany = append(b.hasMany, r)
+	return
+}
+
+// SortField is the integer field of the children that is set to the order of the rows when saving
+func (b *HasManyBuilder) SortField(v string) (r *HasManyBuilder) {
+	b.sortField = v
+	return b
+}
+
+func (b *FieldBuilders) without(name string) (r FieldBuilders) {
+	r = *b.Clone()
+	for _, f := range b.fields {
+		if f.name != name {
+			r.fields = append(r.fields, f)
+		}
+	}
+	return
+}
+
+func (b *EditingBuilder) hasManyField(fieldName string) *HasManyBuilder {
+	for _, hm := range b.hasMany {
+		if hm.fieldName == fieldName {
+			return hm
+		}
+	}
+	return nil
+}
+
+// hasManyRowsFieldName is the form field of the number of the rows, the values of the removed rows might
+// be still in the form, so the rows after it are skipped
+func hasManyRowsFieldName(fieldName string) string {
+	return "__HasManyRows_" + fieldName
+}
+
+func (b *HasManyBuilder) rowPath(i int, fieldName string) string {
+	return fmt.Sprintf("%s[%d].%s", b.fieldName, i, fieldName)
+}
+
+func (b *HasManyBuilder) newElem() reflect.Value {
+	if b.sliceType.Elem().Kind() == reflect.Ptr {
+		return reflect.New(b.elemType)
+	}
+	return reflect.New(b.elemType).Elem()
+}
+
+func (b *HasManyBuilder) component(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+	mb := b.eb.mb
+	id := ctx.Event.Params[0]
+	vErr, _ := ctx.Flash.(*web.ValidationErrors)
+	if vErr == nil {
+		vErr = &web.ValidationErrors{}
+	}
+
+	rows := reflect.ValueOf(reflectutils.MustGet(obj, b.fieldName))
+	hasID := reflectutils.GetType(reflect.New(b.elemType).Interface(), "ID") != nil
+
+	var comps []h.HTMLComponent
+	for i := 0; i < rows.Len(); i++ {
+		var rowComps []h.HTMLComponent
+		if hasID {
+			rowComps = append(rowComps, h.Input("").Type("hidden").
+				Value(fmt.Sprint(reflectutils.MustGet(obj, b.rowPath(i, "ID")))).
+				Attr(web.VFieldName(b.rowPath(i, "ID"))...))
+		}
+		for _, f := range b.fields {
+			if f.compFunc == nil {
+				continue
+			}
+			label := i18n.PT(ctx.R, ModelsI18nModuleKey, mb.label, b.getLabel(f.NameLabel))
+			if isRequired(reflect.New(b.elemType).Interface(), f.name) {
+				label += " *"
+			}
+			path := b.rowPath(i, f.name)
+			rowComps = append(rowComps, f.compFunc(obj, &FieldContext{
+				ModelInfo: mb.Info(),
+				Name:      path,
+				Label:     label,
+				Errors:    vErr.GetFieldErrors(path),
+				Context:   f.context,
+			}, ctx))
+		}
+
+		comps = append(comps, VCard(
+			VCardText(rowComps...).Class("pb-0"),
+			VCardActions(
+				VSpacer(),
+				VBtn("").Icon(true).Small(true).Disabled(i == 0).Children(VIcon("arrow_upward")).
+					Attr("@click", web.Plaid().EventFunc(actions.MoveHasManyRow, id, b.fieldName, fmt.Sprint(i), fmt.Sprint(i-1)).Go()),
+				VBtn("").Icon(true).Small(true).Disabled(i == rows.Len()-1).Children(VIcon("arrow_downward")).
+					Attr("@click", web.Plaid().EventFunc(actions.MoveHasManyRow, id, b.fieldName, fmt.Sprint(i), fmt.Sprint(i+1)).Go()),
+				VBtn("").Icon(true).Small(true).Children(VIcon("delete")).
+					Attr("@click", web.Plaid().EventFunc(actions.RemoveHasManyRow, id, b.fieldName, fmt.Sprint(i)).Go()),
+			),
+		).Outlined(true).Class("mb-2"))
+	}
+
+	return h.Div(
+		h.Div(h.Text(field.Label)).Class("text-subtitle-1 mb-2"),
+		h.Input("").Type("hidden").Value(fmt.Sprint(rows.Len())).Attr(web.VFieldName(hasManyRowsFieldName(b.fieldName))...),
+		h.Components(comps...),
+		VBtn(msgr.HasManyAddRow).Text(true).Small(true).Color("primary").Children(VIcon("add").Left(true)).
+			Attr("@click", web.Plaid().EventFunc(actions.AddHasManyRow, id, b.fieldName).Go()),
+	).Class("mb-4")
+}
+
+// set sets the rows in the form to the children of obj, the children are matched by ID that the fields
+// not in the form are kept, the rows are not changed if they are not in the form
+func (b *HasManyBuilder) set(obj interface{}, newObj interface{}, vErr *web.ValidationErrors, ctx *web.EventContext) {
+	n, err := strconv.Atoi(ctx.R.FormValue(hasManyRowsFieldName(b.fieldName)))
+	if err != nil {
+		return
+	}
+
+	old := reflect.ValueOf(reflectutils.MustGet(obj, b.fieldName))
+	submitted := reflect.ValueOf(reflectutils.MustGet(newObj, b.fieldName))
+	hasID := reflectutils.GetType(reflect.New(b.elemType).Interface(), "ID") != nil
+
+	children := reflect.MakeSlice(b.sliceType, 0, n)
+	for i := 0; i < n; i++ {
+		row := b.newElem()
+		if i < submitted.Len() && !(row.Kind() == reflect.Ptr && submitted.Index(i).IsNil()) {
+			row = submitted.Index(i)
+		}
+
+		child := b.newElem()
+		if hasID && !reflect.ValueOf(reflectutils.MustGet(row.Interface(), "ID")).IsZero() {
+			for j := 0; j < old.Len(); j++ {
+				if reflectutils.MustGet(old.Index(j).Interface(), "ID") == reflectutils.MustGet(row.Interface(), "ID") {
+					child = old.Index(j)
+					break
+				}
+			}
+		}
+		children = reflect.Append(children, child)
+		child = children.Index(i)
+		if child.Kind() != reflect.Ptr {
+			child = child.Addr()
+			row = row.Addr()
+		}
+
+		for _, f := range b.fields {
+			if f.setterFunc == nil {
+				_ = reflectutils.Set(child.Interface(), f.name, reflectutils.MustGet(row.Interface(), f.name))
+			}
+		}
+		if len(b.sortField) > 0 {
+			_ = reflectutils.Set(child.Interface(), b.sortField, i)
+		}
+	}
+	_ = reflectutils.Set(obj, b.fieldName, children.Interface())
+
+	msgr := MustGetMessages(ctx.R)
+	for i := 0; i < n; i++ {
+		child := reflectutils.MustGet(obj, fmt.Sprintf("%s[%d]", b.fieldName, i))
+		for _, f := range b.fields {
+			path := b.rowPath(i, f.name)
+			if f.setterFunc != nil {
+				err1 := f.setterFunc(obj, &FieldContext{
+					ModelInfo: b.eb.mb.Info(),
+					Name:      path,
+					Label:     b.getLabel(f.NameLabel),
+					Context:   f.context,
+				}, ctx)
+				if err1 != nil {
+					vErr.FieldError(path, err1.Error())
+					continue
+				}
+			}
+			if msg := validateField(msgr, child, f.name); len(msg) > 0 {
+				vErr.FieldError(path, msg)
+			}
+		}
+	}
+}
+
+func (b *EditingBuilder) addHasManyRow(ctx *web.EventContext) (r web.EventResponse, err error) {
+	return b.updateHasManyRows(ctx, func(hm *HasManyBuilder, rows reflect.Value) reflect.Value {
+		return reflect.Append(rows, hm.newElem())
+	})
+}
+
+func (b *EditingBuilder) removeHasManyRow(ctx *web.EventContext) (r web.EventResponse, err error) {
+	i, err := strconv.Atoi(ctx.Event.Params[2])
+	if err != nil {
+		return
+	}
+	return b.updateHasManyRows(ctx, func(hm *HasManyBuilder, rows reflect.Value) reflect.Value {
+		if i < 0 || i >= rows.Len() {
+			return rows
+		}
+		return reflect.AppendSlice(rows.Slice(0, i), rows.Slice(i+1, rows.Len()))
+	})
+}
+
+func (b *EditingBuilder) moveHasManyRow(ctx *web.EventContext) (r web.EventResponse, err error) {
+	from, err := strconv.Atoi(ctx.Event.Params[2])
+	if err != nil {
+		return
+	}
+	to, err := strconv.Atoi(ctx.Event.Params[3])
+	if err != nil {
+		return
+	}
+	return b.updateHasManyRows(ctx, func(hm *HasManyBuilder, rows reflect.Value) reflect.Value {
+		if from < 0 || from >= rows.Len() || to < 0 || to >= rows.Len() {
+			return rows
+		}
+		f, t := rows.Index(from).Interface(), rows.Index(to).Interface()
+		rows.Index(from).Set(reflect.ValueOf(t))
+		rows.Index(to).Set(reflect.ValueOf(f))
+		return rows
+	})
+}
+
+// updateHasManyRows renders the form with the rows changed by update, and the values in the form,
+// nothing is saved until the form is submitted
+func (b *EditingBuilder) updateHasManyRows(ctx *web.EventContext, update func(hm *HasManyBuilder, rows reflect.Value) reflect.Value) (r web.EventResponse, err error) {
+	id := ctx.Event.Params[0]
+	usingB := b
+	if b.mb.creating != nil && len(id) == 0 {
+		usingB = b.mb.creating
+	}
+
+	hm := usingB.hasManyField(ctx.Event.Params[1])
+	if hm == nil {
+		err = fmt.Errorf("%s is not a has-many field of %s", ctx.Event.Params[1], b.mb.label)
+		return
+	}
+
+	// the validation errors are shown when the form is submitted
+	obj, err := usingB.fetchAndSet(id, ctx)
+	if _, ok := err.(*web.ValidationErrors); err != nil && !ok {
+		return
+	}
+	err = nil
+	usingB.keepLockVersion(obj, ctx)
+
+	rows := update(hm, reflect.ValueOf(reflectutils.MustGet(obj, hm.fieldName)))
+	_ = reflectutils.Set(obj, hm.fieldName, rows.Interface())
+
+	// the params of the form are the id only
+	ctx.Event.Params = ctx.Event.Params[:1]
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: usingB.formPortalName(),
+		Body: usingB.editFormFor(obj, ctx),
+	})
+	return
+}
@@ -0,0 +1,576 @@
+package presets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/i18n"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/iancoleman/strcase"
+	h "github.com/theplant/htmlgo"
+)
+
+const importFileFieldName = "ImportFile"
+const importTokenFieldName = "ImportToken"
+const importColumnFieldNamePrefix = "ImportColumn_"
+const importPreviewRows = 100
+const importFileExpiration = time.Hour
+
+// ImportFileStore keeps the uploaded CSV files between the steps of importing, by the token of the upload
+type ImportFileStore interface {
+	PutImportFile(userID string, content []byte) (token string, err error)
+	// GetImportFile returns nil content if the file is not found or expired
+	GetImportFile(userID string, token string) (content []byte, err error)
+	DeleteImportFile(userID string, token string) (err error)
+}
+
+// ImportFileStore replaces the default memory store, which doesn't work for multiple server instances
+func (b *Builder) ImportFileStore(v ImportFileStore) (r *Builder) {
+	b.importFileStore = v
+	return b
+}
+
+type memoryImportFile struct {
+	userID    string
+	content   []byte
+	expiresAt time.Time
+}
+
+type MemoryImportFileStore struct {
+	mu    sync.Mutex
+	files map[string]*memoryImportFile
+}
+
+func NewMemoryImportFileStore() (r *MemoryImportFileStore) {
+	return &MemoryImportFileStore{files: map[string]*memoryImportFile{}}
+}
+
+func (s *MemoryImportFileStore) PutImportFile(userID string, content []byte) (token string, err error) {
+	bs := make([]byte, 16)
+	_, err = rand.Read(bs)
+	if err != nil {
+		return
+	}
+	token = hex.EncodeToString(bs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, f := range s.files {
+		if now.After(f.expiresAt) {
+			delete(s.files, k)
+		}
+	}
+	s.files[token] = &memoryImportFile{userID: userID, content: content, expiresAt: now.Add(importFileExpiration)}
+	return
+}
+
+func (s *MemoryImportFileStore) GetImportFile(userID string, token string) (content []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.files[token]
+	if f == nil || f.userID != userID || time.Now().After(f.expiresAt) {
+		return
+	}
+	return f.content, nil
+}
+
+func (s *MemoryImportFileStore) DeleteImportFile(userID string, token string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f := s.files[token]; f != nil && f.userID == userID {
+		delete(s.files, token)
+	}
+	return
+}
+
+type ImportingBuilder struct {
+	mb         *ModelBuilder
+	fieldNames []string
+}
+
+// Importing adds an import button to the listing toolbar, that imports records from a CSV file.
+// every row is created with the field setters, validator and saver of the creating form,
+// vs limits the fields that columns can be mapped to, default is all the fields of the creating form
+func (b *ModelBuilder) Importing(vs ...string) (r *ImportingBuilder) {
+	if b.importing == nil {
+		b.importing = &ImportingBuilder{mb: b}
+	}
+	r = b.importing
+	if len(vs) > 0 {
+		r.fieldNames = vs
+	}
+	return
+}
+
+type importRow struct {
+	line   int
+	cells  []string
+	errors []string
+}
+
+type importField struct {
+	Name  string
+	Label string
+}
+
+func (b *ImportingBuilder) editingBuilder() (r *EditingBuilder) {
+	r = b.mb.editing
+	if b.mb.creating != nil {
+		r = b.mb.creating
+	}
+	if len(b.fieldNames) == 0 {
+		return
+	}
+	return &EditingBuilder{
+		mb:            r.mb,
+		fetcher:       r.fetcher,
+		setter:        r.setter,
+		saver:         r.saver,
+		deleter:       r.deleter,
+		validator:     r.validator,
+		FieldBuilders: *r.FieldBuilders.Only(b.fieldNames...),
+	}
+}
+
+func (b *ImportingBuilder) fields(ctx *web.EventContext) (r []importField) {
+	eb := b.editingBuilder()
+	for _, f := range eb.fields {
+		r = append(r, importField{
+			Name:  f.name,
+			Label: i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, b.mb.getLabel(f.NameLabel)),
+		})
+	}
+	return
+}
+
+func (b *ImportingBuilder) importButton(msgr *Messages, ctx *web.EventContext) h.HTMLComponent {
+	if b == nil {
+		return nil
+	}
+
+	return VBtn(msgr.Import).
+		Depressed(true).
+		Class("ml-2").
+		Disabled(b.mb.Info().Verifier().Do(PermCreate).WithReq(ctx.R).IsAllowed() != nil).
+		OnClick(actions.DrawerImport)
+}
+
+func (b *ImportingBuilder) drawerImport(ctx *web.EventContext) (r web.EventResponse, err error) {
+	b.mb.p.rightDrawer(&r, b.importForm(b.uploadStep(ctx), ctx))
+	return
+}
+
+func (b *ImportingBuilder) importForm(step h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+
+	return h.Components(
+		VAppBar(
+			VToolbarTitle(msgr.ImportingObjectsTitle(
+				i18n.T(ctx.R, ModelsI18nModuleKey, b.mb.label),
+			)).Class("pl-2"),
+			VSpacer(),
+			VBtn("").Icon(true).Children(
+				VIcon("close"),
+			).Attr("@click.stop", "vars.rightDrawer = false"),
+		).Color("white").Elevation(0).Dense(true),
+
+		VContainer(
+			VCard(step).Flat(true),
+		).Fluid(true),
+	)
+}
+
+func (b *ImportingBuilder) updateImportForm(r *web.EventResponse, step h.HTMLComponent, ctx *web.EventContext) {
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: rightDrawerContentPortalName,
+		Body: b.importForm(step, ctx),
+	})
+}
+
+func (b *ImportingBuilder) uploadStep(ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+
+	var alert h.HTMLComponent
+	if msg, ok := ctx.Flash.(string); ok {
+		alert = VAlert(h.Text(msg)).Border("left").Type("error").Elevation(2).ColoredBorder(true)
+	}
+
+	return h.Components(
+		VCardText(
+			alert,
+			VFileInput().
+				FieldName(importFileFieldName).
+				Label(msgr.ImportCSVFile).
+				Attr("accept", ".csv,text/csv"),
+		),
+		VCardActions(
+			VSpacer(),
+			VBtn(msgr.ImportNext).
+				Dark(true).
+				Color("primary").
+				Attr("@click", web.Plaid().
+					EventFunc(actions.ImportUpload).
+					URL(b.mb.Info().ListingHref()).
+					Go()),
+		),
+	)
+}
+
+// importUpload reads the uploaded file and shows the column mapping,
+// the file is kept in the ImportFileStore for the following steps
+func (b *ImportingBuilder) importUpload(ctx *web.EventContext) (r web.EventResponse, err error) {
+	msgr := MustGetMessages(ctx.R)
+
+	f, _, err1 := ctx.R.FormFile(importFileFieldName)
+	if err1 != nil {
+		ctx.Flash = msgr.ImportCSVFileRequired
+		b.updateImportForm(&r, b.uploadStep(ctx), ctx)
+		return
+	}
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		return
+	}
+
+	header, _, err1 := readImportCSV(string(content))
+	if err1 != nil || len(header) == 0 {
+		ctx.Flash = msgr.ImportInvalidCSVFile
+		b.updateImportForm(&r, b.uploadStep(ctx), ctx)
+		return
+	}
+
+	token, err := b.mb.p.importFileStore.PutImportFile(b.mb.p.userID(ctx.R), content)
+	if err != nil {
+		return
+	}
+
+	b.updateImportForm(&r, b.mappingStep(token, header, ctx), ctx)
+	return
+}
+
+func (b *ImportingBuilder) mappingStep(token string, header []string, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+
+	fields := b.fields(ctx)
+	items := append([]importField{{Name: "", Label: msgr.ImportSkipColumn}}, fields...)
+
+	var selects []h.HTMLComponent
+	for i, col := range header {
+		selects = append(selects,
+			VSelect().
+				FieldName(fmt.Sprintf("%s%d", importColumnFieldNamePrefix, i)).
+				Label(col).
+				Items(items).
+				ItemText("Label").
+				ItemValue("Name").
+				Value(matchImportField(col, fields)),
+		)
+	}
+
+	return h.Components(
+		importTokenInput(token),
+		VCardTitle(h.Text(msgr.ImportColumnMapping)),
+		VCardText(selects...),
+		VCardActions(
+			VSpacer(),
+			VBtn(msgr.ImportNext).
+				Dark(true).
+				Color("primary").
+				Attr("@click", web.Plaid().
+					EventFunc(actions.ImportPreview).
+					URL(b.mb.Info().ListingHref()).
+					Go()),
+		),
+	)
+}
+
+// matchImportField guesses the field of a column by its name or label
+func matchImportField(col string, fields []importField) string {
+	col = strings.TrimSpace(col)
+	for _, f := range fields {
+		if strings.EqualFold(col, f.Name) ||
+			strings.EqualFold(col, f.Label) ||
+			strings.EqualFold(col, strcase.ToSnake(f.Name)) {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+func importTokenInput(token string) h.HTMLComponent {
+	return h.Input("").Type("hidden").Value(token).Attr(web.VFieldName(importTokenFieldName)...)
+}
+
+func (b *ImportingBuilder) importPreview(ctx *web.EventContext) (r web.EventResponse, err error) {
+	content, err := b.uploadedFile(ctx)
+	if err != nil || content == nil {
+		return b.uploadAgain(ctx)
+	}
+
+	result, err := b.runImport(string(content), ctx, true)
+	if err != nil {
+		return
+	}
+
+	b.updateImportForm(&r, b.resultStep(result, ctx), ctx)
+	return
+}
+
+func (b *ImportingBuilder) doImport(ctx *web.EventContext) (r web.EventResponse, err error) {
+	content, err := b.uploadedFile(ctx)
+	if err != nil || content == nil {
+		return b.uploadAgain(ctx)
+	}
+
+	result, err := b.runImport(string(content), ctx, false)
+	if err != nil {
+		return
+	}
+	err = b.mb.p.importFileStore.DeleteImportFile(b.mb.p.userID(ctx.R), ctx.R.FormValue(importTokenFieldName))
+	if err != nil {
+		return
+	}
+
+	b.updateImportForm(&r, b.resultStep(result, ctx), ctx)
+	r.PushState = web.PushState(nil)
+	return
+}
+
+func (b *ImportingBuilder) uploadedFile(ctx *web.EventContext) (content []byte, err error) {
+	return b.mb.p.importFileStore.GetImportFile(b.mb.p.userID(ctx.R), ctx.R.FormValue(importTokenFieldName))
+}
+
+// uploadAgain shows the upload step when the uploaded file is expired
+func (b *ImportingBuilder) uploadAgain(ctx *web.EventContext) (r web.EventResponse, err error) {
+	ctx.Flash = MustGetMessages(ctx.R).ImportFileExpired
+	b.updateImportForm(&r, b.uploadStep(ctx), ctx)
+	return
+}
+
+type importResult struct {
+	token   string
+	header  []string
+	rows    []*importRow
+	mapping []string
+	dryRun  bool
+	// stoppedAt is the row that failed to save, the rows after it are not imported
+	stoppedAt *importRow
+}
+
+// runImport sets every row to a new object with the creating form pipeline,
+// and saves the valid ones unless dryRun. rows are saved one by one, so the import stops
+// at the first row that fails to save, and the result tells the rows that are imported before it
+func (b *ImportingBuilder) runImport(content string, ctx *web.EventContext, dryRun bool) (r *importResult, err error) {
+	r = &importResult{token: ctx.R.FormValue(importTokenFieldName), dryRun: dryRun}
+	r.header, r.rows, err = readImportCSV(content)
+	if err != nil {
+		return
+	}
+
+	for i := range r.header {
+		r.mapping = append(r.mapping, ctx.R.FormValue(fmt.Sprintf("%s%d", importColumnFieldNamePrefix, i)))
+	}
+
+	msgr := MustGetMessages(ctx.R)
+	eb := b.editingBuilder()
+	for _, row := range r.rows {
+		if r.stoppedAt != nil {
+			row.errors = []string{msgr.ImportNotImported}
+			continue
+		}
+
+		rowCtx := importRowContext(row, r.mapping, ctx)
+		obj, err1 := eb.fetchAndSet("", rowCtx)
+		if err1 != nil {
+			row.errors = b.rowErrors(err1, eb, ctx)
+			continue
+		}
+		if dryRun {
+			continue
+		}
+
+		err1 = eb.save(obj, "", rowCtx)
+		if err1 != nil {
+			row.errors = b.rowErrors(err1, eb, ctx)
+			r.stoppedAt = row
+		}
+	}
+	return
+}
+
+// importRowContext builds a context whose submitted form is the row, so that field setters
+// that read ctx.R work the same as with the creating form
+func importRowContext(row *importRow, mapping []string, ctx *web.EventContext) *web.EventContext {
+	values := url.Values{}
+	for i, name := range mapping {
+		if len(name) == 0 || i >= len(row.cells) {
+			continue
+		}
+		values.Set(name, row.cells[i])
+	}
+
+	return formContext(values, ctx)
+}
+
+func (b *ImportingBuilder) rowErrors(err error, eb *EditingBuilder, ctx *web.EventContext) (r []string) {
+	vErr, ok := err.(*web.ValidationErrors)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	r = append(r, vErr.GetGlobalErrors()...)
+	for _, f := range eb.fields {
+		for _, msg := range vErr.GetFieldErrors(f.name) {
+			r = append(r, fmt.Sprintf("%s: %s",
+				i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, b.mb.getLabel(f.NameLabel)), msg))
+		}
+	}
+	return
+}
+
+func (b *ImportingBuilder) resultStep(result *importResult, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+	header, rows, mapping, dryRun := result.header, result.rows, result.mapping, result.dryRun
+
+	var failed int
+	for _, row := range rows {
+		if len(row.errors) > 0 {
+			failed++
+		}
+	}
+
+	labels := map[string]string{}
+	for _, f := range b.fields(ctx) {
+		labels[f.Name] = f.Label
+	}
+
+	var ths = []h.HTMLComponent{h.Th(msgr.ImportRow)}
+	for _, name := range mapping {
+		if len(name) > 0 {
+			ths = append(ths, h.Th(labels[name]))
+		}
+	}
+	ths = append(ths, h.Th(msgr.ImportErrors))
+
+	var trs []h.HTMLComponent
+	for i, row := range rows {
+		if i >= importPreviewRows {
+			break
+		}
+		var tds = []h.HTMLComponent{h.Td(h.Text(fmt.Sprint(row.line)))}
+		for j, name := range mapping {
+			if len(name) == 0 {
+				continue
+			}
+			var v string
+			if j < len(row.cells) {
+				v = row.cells[j]
+			}
+			tds = append(tds, h.Td(h.Text(v)))
+		}
+		tds = append(tds, h.Td(h.Text(strings.Join(row.errors, "; "))).Class("error--text"))
+		trs = append(trs, h.Tr(tds...))
+	}
+
+	var summary = msgr.ImportDone(len(rows)-failed, failed)
+	var alertType = "success"
+	if dryRun {
+		summary = msgr.ImportPreviewSummary(len(rows)-failed, failed)
+		alertType = "info"
+	}
+	if failed > 0 {
+		alertType = "warning"
+	}
+	if result.stoppedAt != nil {
+		summary = msgr.ImportStopped(result.stoppedAt.line, len(rows)-failed)
+		alertType = "error"
+	}
+
+	var commitBtn h.HTMLComponent
+	if dryRun && len(rows) > failed {
+		commitBtn = VBtn(msgr.Import).
+			Dark(true).
+			Color("primary").
+			Attr("@click", web.Plaid().
+				EventFunc(actions.DoImport).
+				URL(b.mb.Info().ListingHref()).
+				Go())
+	}
+
+	var reportBtn h.HTMLComponent
+	if failed > 0 {
+		reportBtn = VBtn(msgr.ImportErrorReport).
+			Depressed(true).
+			Href(importErrorReportHref(header, rows, msgr)).
+			Attr("download", fmt.Sprintf("%s-import-errors.csv", b.mb.uriName))
+	}
+
+	return h.Components(
+		importTokenInput(result.token),
+		VCardText(
+			VAlert(h.Text(summary)).Type(alertType).Text(true),
+			VSimpleTable(
+				h.Thead(h.Tr(ths...)),
+				h.Tbody(trs...),
+			).Dense(true),
+		),
+		VCardActions(
+			reportBtn,
+			VSpacer(),
+			commitBtn,
+		),
+	)
+}
+
+// importErrorReportHref returns a data url of the csv file with the rows that have errors,
+// the original cells are kept so that it can be fixed and imported again
+func importErrorReportHref(header []string, rows []*importRow, msgr *Messages) string {
+	var buf bytes.Buffer
+	// utf-8 BOM makes Excel detect the encoding of translated headers
+	buf.WriteString("\xEF\xBB\xBF")
+	w := csv.NewWriter(&buf)
+	_ = w.Write(append([]string{msgr.ImportRow, msgr.ImportErrors}, header...))
+	for _, row := range rows {
+		if len(row.errors) == 0 {
+			continue
+		}
+		_ = w.Write(append([]string{fmt.Sprint(row.line), strings.Join(row.errors, "; ")}, row.cells...))
+	}
+	w.Flush()
+
+	return "data:text/csv;charset=utf-8," + url.PathEscape(buf.String())
+}
+
+// readImportCSV returns the header and the rows of content, blank lines are skipped
+func readImportCSV(content string) (header []string, rows []*importRow, err error) {
+	content = strings.TrimPrefix(content, "\xEF\xBB\xBF")
+	r := csv.NewReader(strings.NewReader(content))
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	header = records[0]
+	for i, rec := range records[1:] {
+		rows = append(rows, &importRow{line: i + 2, cells: rec})
+	}
+	return
+}
@@ -0,0 +1,187 @@
+package presets
+
+import (
+	"fmt"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/i18n"
+	"github.com/goplaid/x/perm"
+	"github.com/goplaid/x/presets/actions"
+	s "github.com/goplaid/x/stripeui"
+	h "github.com/theplant/htmlgo"
+)
+
+// InlineEditFields makes the cells of these fields editable in place with their editing components,
+// pressing enter saves the field with the setter, validator and saver of the editing form
+func (b *ListingBuilder) InlineEditFields(vs ...string) (r *ListingBuilder) {
+	b.inlineEditFields = vs
+	return b
+}
+
+func (b *ListingBuilder) isInlineEditable(fieldName string) bool {
+	for _, f := range b.inlineEditFields {
+		if f == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+func inlineEditPortalName(fieldName string, id string) string {
+	return fmt.Sprintf("inlineEdit_%s_%s", fieldName, id)
+}
+
+func (b *ListingBuilder) inlineEditCellComponentFunc(f *FieldBuilder) s.CellComponentFunc {
+	return func(obj interface{}, fieldName string, ctx *web.EventContext) h.HTMLComponent {
+		if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn(f.name).WithReq(ctx.R).IsAllowed() != nil {
+			return f.compFunc(obj, b.mb.getComponentFuncField(f), ctx)
+		}
+
+		id := b.mb.objectID(obj)
+		return h.Td(
+			web.Portal(b.inlineEditCell(f, obj, id, ctx)).Name(inlineEditPortalName(f.name, id)),
+		)
+	}
+}
+
+// inlineEditCell renders the listing cell as a clickable div to start editing
+func (b *ListingBuilder) inlineEditCell(f *FieldBuilder, obj interface{}, id string, ctx *web.EventContext) h.HTMLComponent {
+	return h.Div(tdToDiv(f.compFunc(obj, b.mb.getComponentFuncField(f), ctx))).
+		Style("cursor: pointer; min-height: 24px;").
+		Attr("@click.stop", web.Plaid().
+			EventFunc(actions.InlineEdit, id, f.name).
+			Go())
+}
+
+func (b *ListingBuilder) inlineEditingBuilder(fieldNames ...string) (r *EditingBuilder) {
+	eb := b.mb.editing
+	return &EditingBuilder{
+		mb:            eb.mb,
+		fetcher:       eb.fetcher,
+		setter:        eb.setter,
+		saver:         eb.saver,
+		deleter:       eb.deleter,
+		validator:     eb.validator,
+		lockVersion:   eb.lockVersion,
+		FieldBuilders: *eb.FieldBuilders.Only(fieldNames...),
+	}
+}
+
+// updateFields sets the fields of the inline editing builder from the form and saves them.
+// fetchAndSet skips fields that are not allowed to update, but here they are the only fields
+func (b *ListingBuilder) updateFields(eb *EditingBuilder, id string, ctx *web.EventContext) (obj interface{}, err error) {
+	obj, err = eb.fetchAndSet(id, ctx)
+	if err != nil {
+		return
+	}
+	for _, f := range eb.fields {
+		if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn(f.name).WithReq(ctx.R).IsAllowed() != nil {
+			err = perm.PermissionDenied
+			return
+		}
+	}
+	err = eb.save(obj, id, ctx)
+	return
+}
+
+func (b *ListingBuilder) inlineEditor(eb *EditingBuilder, obj interface{}, id string, vErr *web.ValidationErrors, ctx *web.EventContext) h.HTMLComponent {
+	f := eb.fields[0]
+	if vErr == nil {
+		vErr = &web.ValidationErrors{}
+	}
+
+	var errs []h.HTMLComponent
+	for _, msg := range vErr.GetGlobalErrors() {
+		errs = append(errs, h.Div(h.Text(msg)).Class("error--text caption"))
+	}
+
+	return h.Div(
+		f.compFunc(obj, &FieldContext{
+			ModelInfo: b.mb.Info(),
+			Name:      f.name,
+			Label:     i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, b.mb.getLabel(f.NameLabel)),
+			Errors:    vErr.GetFieldErrors(f.name),
+			Context:   f.context,
+		}, ctx),
+		h.Components(errs...),
+	).Attr("@click.stop", "").
+		Attr("@keyup.enter", web.Plaid().
+			EventFunc(actions.DoInlineEdit, id, f.name).
+			Go()).
+		Attr("@keyup.esc", web.Plaid().
+			EventFunc(actions.CancelInlineEdit, id, f.name).
+			Go())
+}
+
+func (b *ListingBuilder) inlineEditParams(ctx *web.EventContext) (id string, f *FieldBuilder, err error) {
+	id, fieldName := ctx.Event.Params[0], ctx.Event.Params[1]
+	f = b.getField(fieldName)
+	if f == nil || !b.isInlineEditable(fieldName) {
+		err = fmt.Errorf("field %s is not inline editable", fieldName)
+	}
+	return
+}
+
+func (b *ListingBuilder) updateInlineEditPortal(r *web.EventResponse, fieldName string, id string, body h.HTMLComponent) {
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: inlineEditPortalName(fieldName, id),
+		Body: body,
+	})
+}
+
+func (b *ListingBuilder) inlineEdit(ctx *web.EventContext) (r web.EventResponse, err error) {
+	id, f, err := b.inlineEditParams(ctx)
+	if err != nil {
+		return
+	}
+
+	obj, err := b.mb.editing.fetcher(b.mb.newModel(), id, ctx)
+	if err != nil {
+		return
+	}
+	if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn(f.name).WithReq(ctx.R).IsAllowed() != nil {
+		err = perm.PermissionDenied
+		return
+	}
+
+	b.updateInlineEditPortal(&r, f.name, id, b.inlineEditor(b.inlineEditingBuilder(f.name), obj, id, nil, ctx))
+	return
+}
+
+func (b *ListingBuilder) cancelInlineEdit(ctx *web.EventContext) (r web.EventResponse, err error) {
+	id, f, err := b.inlineEditParams(ctx)
+	if err != nil {
+		return
+	}
+
+	obj, err := b.mb.editing.fetcher(b.mb.newModel(), id, ctx)
+	if err != nil {
+		return
+	}
+
+	b.updateInlineEditPortal(&r, f.name, id, b.inlineEditCell(f, obj, id, ctx))
+	return
+}
+
+func (b *ListingBuilder) doInlineEdit(ctx *web.EventContext) (r web.EventResponse, err error) {
+	id, f, err := b.inlineEditParams(ctx)
+	if err != nil {
+		return
+	}
+
+	eb := b.inlineEditingBuilder(f.name)
+	obj, err1 := b.updateFields(eb, id, ctx)
+
+	if err1 != nil {
+		vErr, ok := err1.(*web.ValidationErrors)
+		if !ok {
+			vErr = &web.ValidationErrors{}
+			vErr.GlobalError(err1.Error())
+		}
+		b.updateInlineEditPortal(&r, f.name, id, b.inlineEditor(eb, obj, id, vErr, ctx))
+		return
+	}
+
+	b.updateInlineEditPortal(&r, f.name, id, b.inlineEditCell(f, obj, id, ctx))
+	return
+}
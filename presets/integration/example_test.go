@@ -1,16 +1,28 @@
 package integration_test
 
 import (
+	"archive/zip"
+	"bufio"
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/perm"
+	"github.com/goplaid/x/presets"
 	examples2 "github.com/goplaid/x/presets/examples"
+	"github.com/goplaid/x/presets/gorm2op"
+	"github.com/goplaid/x/presets/storage"
 	"github.com/theplant/gofixtures"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -20,13 +32,75 @@ var customerData = gofixtures.Data(gofixtures.Sql(`
 				insert into customers (id, name) values (11, 'Felix1');
 			`, []string{"customers"}))
 
+var paymentsData = gofixtures.Data(gofixtures.Sql(`
+				insert into payments (id, customer_id, currency_code, amount) values (1, 11, 'USD', 100);
+				insert into payments (id, customer_id, currency_code, amount) values (2, 11, 'CNY', 300);
+				insert into payments (id, customer_id, currency_code, amount) values (3, 11, 'USD', 50);
+				insert into payments (id, customer_id, currency_code, amount) values (4, 11, 'USD', 25);
+			`, []string{"payments"}))
+
+var ticketsData = gofixtures.Data(gofixtures.Sql(`
+				insert into tickets (id, title, assignee, status) values (1, 'Login fails', 'Felix', 'open');
+				insert into tickets (id, title, assignee, status) values (2, 'Typo in footer', '', 'open');
+				insert into tickets (id, title, assignee, status) values (3, 'Slow listing', 'Anna', 'in_progress');
+			`, []string{"tickets"}))
+
+var fullTextTicketsData = gofixtures.Data(gofixtures.Sql(`
+				insert into tickets (id, title, assignee, status) values (1, 'Slow export', '', 'open');
+				insert into tickets (id, title, assignee, status) values (2, 'Export fails sometimes when the listing is huge and slow', 'Felix', 'open');
+				insert into tickets (id, title, assignee, status) values (3, 'Typo in footer', 'Anna', 'open');
+			`, []string{"tickets"}))
+
+var appointmentsData = gofixtures.Data(gofixtures.Sql(`
+				insert into appointments (id, title, start_at, end_at) values (1, 'Dentist', '2026-10-05 10:00:00+08:00', '2026-10-05 11:30:00+08:00');
+				insert into appointments (id, title, start_at, end_at) values (2, 'Haircut', '2026-10-20 15:00:00+08:00', null);
+				insert into appointments (id, title, start_at, end_at) values (3, 'Next Month', '2026-11-20 15:00:00+08:00', null);
+			`, []string{"appointments"}))
+
+var categoriesData = gofixtures.Data(gofixtures.Sql(`
+				insert into categories (id, name, parent_id) values (1, 'Root A', 0);
+				insert into categories (id, name, parent_id) values (2, 'Child A1', 1);
+				insert into categories (id, name, parent_id) values (3, 'Grandchild', 2);
+				insert into categories (id, name, parent_id) values (4, 'Root B', 0);
+			`, []string{"categories"}))
+
 var productData = gofixtures.Data(gofixtures.Sql(`
 				insert into products (id, name) values (12, 'Product 1');
 			`, []string{"products"}))
 
+var twoCustomersData = gofixtures.Data(gofixtures.Sql(`
+				insert into customers (id, name) values (11, 'Felix1');
+				insert into customers (id, name) values (12, 'Anna2');
+			`, []string{"customers"}))
+
+var fiveProductsData = gofixtures.Data(gofixtures.Sql(`
+				insert into products (id, name) values (1, 'Product1'), (2, 'Product2'), (3, 'Product3'), (4, 'Product4'), (5, 'Product5');
+			`, []string{"products"}))
+
+var matchingCustomersData = gofixtures.Data(gofixtures.Sql(`
+				insert into customers (id, name) values (1, 'match1'), (2, 'match2'), (3, 'match3'), (4, 'match4'), (5, 'match5'), (6, 'match6'), (7, 'other7');
+			`, []string{"customers"}))
+
+var emptyListingViewsData = gofixtures.Data(gofixtures.Sql(``, []string{"presets_listing_views"}))
+var emptyListingColumnsData = gofixtures.Data(gofixtures.Sql(``, []string{"presets_listing_columns"}))
+
 var emptyCustomerData = gofixtures.Data(gofixtures.Sql(``, []string{"customers"}))
 var creditCardData = gofixtures.Data(customerData, gofixtures.Sql(``, []string{"credit_cards"}))
 
+var customerCreditCardsData = gofixtures.Data(customerData, gofixtures.Sql(`
+				insert into credit_cards (id, customer_id, number, name) values (1, 11, '4111', 'Felix');
+				insert into credit_cards (id, customer_id, number, name) values (2, 11, '5500', 'Felix');
+			`, []string{"credit_cards"}))
+
+var companiesData = gofixtures.Data(gofixtures.Sql(`
+				insert into companies (id, name) values (1, 'Globex'), (2, 'Acme');
+			`, []string{"companies"}))
+
+var productTagsData = gofixtures.Data(productData, gofixtures.Sql(`
+				insert into tags (id, name) values (1, 'Laptop'), (2, 'Sale'), (3, 'New');
+				insert into product_tags (product_id, tag_id) values (12, 1), (12, 2);
+			`, []string{"tags", "product_tags"}))
+
 type reqCase struct {
 	name               string
 	reqFunc            func(db *sql.DB) *http.Request
@@ -77,6 +151,121 @@ Felix11
 			return
 		},
 	},
+	{
+		name: "Inline edit with validation error",
+		reqFunc: func(db *sql.DB) *http.Request {
+			customerData.TruncatePut(db)
+			r := httptest.NewRequest("POST", "/admin/my_customers?__execute_event__=presets_DoInlineEdit", strings.NewReader(`
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="__event_data__"
+
+{"eventFuncId":{"id":"presets_DoInlineEdit","params":["11","Name"],"pushState":null},"event":{}}
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="Name"
+
+abc
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8--
+`))
+			r.Header.Add("Content-Type", `multipart/form-data; boundary=----WebKitFormBoundaryOv2oq9YJ8tIG3xJ8`)
+			return r
+		},
+		eventResponseMatch: func(er *testEventResponse, db *gorm.DB, t *testing.T) {
+			if len(er.UpdatePortals) != 1 || er.UpdatePortals[0].Name != "inlineEdit_Name_11" ||
+				!strings.Contains(er.UpdatePortals[0].Body, "input more than 5 chars") {
+				t.Error("validation error should show inline", er.UpdatePortals)
+			}
+			var u = &examples2.Customer{}
+			db.Find(u, 11)
+			if u.Name != "Felix1" {
+				t.Error("invalid value should not be saved", u)
+			}
+		},
+	},
+	{
+		name: "Inline edit",
+		reqFunc: func(db *sql.DB) *http.Request {
+			customerData.TruncatePut(db)
+			r := httptest.NewRequest("POST", "/admin/my_customers?__execute_event__=presets_DoInlineEdit", strings.NewReader(`
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="__event_data__"
+
+{"eventFuncId":{"id":"presets_DoInlineEdit","params":["11","Name"],"pushState":null},"event":{}}
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="Name"
+
+Felix Inline
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="CompanyID"
+
+42
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8--
+`))
+			r.Header.Add("Content-Type", `multipart/form-data; boundary=----WebKitFormBoundaryOv2oq9YJ8tIG3xJ8`)
+			return r
+		},
+		eventResponseMatch: func(er *testEventResponse, db *gorm.DB, t *testing.T) {
+			if len(er.UpdatePortals) != 1 || !strings.Contains(er.UpdatePortals[0].Body, ">Felix Inline</div>") {
+				t.Error("saved cell should be shown", er.UpdatePortals)
+			}
+			var u = &examples2.Customer{}
+			db.Find(u, 11)
+			if u.Name != "Felix Inline" || u.CompanyID != 0 {
+				t.Error("only the inline edited field should be saved", u)
+			}
+		},
+	},
+	{
+		name: "Offer to select all matching records",
+		reqFunc: func(db *sql.DB) *http.Request {
+			matchingCustomersData.TruncatePut(db)
+			return httptest.NewRequest("GET", "/admin/my_customers?keyword=match&selected=6,5,4,3,2", nil)
+		},
+		pageMatch: func(body *bytes.Buffer, db *gorm.DB, t *testing.T) {
+			if !strings.Contains(body.String(), "Select all 6 matching records") {
+				t.Error("should offer to select all matching records", body.String())
+			}
+		},
+	},
+	{
+		name: "Bulk action on all matching records",
+		reqFunc: func(db *sql.DB) *http.Request {
+			matchingCustomersData.TruncatePut(db)
+			r := httptest.NewRequest("POST", "/admin/my_customers?__execute_event__=presets_DoBulkAction", strings.NewReader(`
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="__event_data__"
+
+{"eventFuncId":{"id":"presets_DoBulkAction","params":["Approve","","keyword=match&selectAllMatching=1"],"pushState":null},"event":{}}
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="ApprovalComment"
+
+approved in bulk
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8--
+`))
+			r.Header.Add("Content-Type", `multipart/form-data; boundary=----WebKitFormBoundaryOv2oq9YJ8tIG3xJ8`)
+			return r
+		},
+		eventResponseMatch: func(er *testEventResponse, db *gorm.DB, t *testing.T) {
+			var approved []*examples2.Customer
+			db.Where("approval_comment = ?", "approved in bulk").Order("id").Find(&approved)
+			if len(approved) != 6 || approved[5].Name != "match6" {
+				t.Error("all the matching records should be approved", approved)
+			}
+		},
+	},
+	{
+		name: "Bulk action without UpdateMatchingFunc on all matching records",
+		reqFunc: func(db *sql.DB) *http.Request {
+			matchingCustomersData.TruncatePut(db)
+			return eventRequest("/admin/my_customers", "presets_DoBulkAction", `["Delete","","keyword=match&selectAllMatching=1"]`)
+		},
+		eventResponseMatch: func(er *testEventResponse, db *gorm.DB, t *testing.T) {
+			var count int64
+			db.Model(&examples2.Customer{}).Count(&count)
+			if len(er.UpdatePortals) != 1 || !strings.Contains(er.UpdatePortals[0].Body, "This action can&#39;t be done on all the matching records") || count == 0 {
+				t.Error("the action should not be done on all matching records", count, er.UpdatePortals)
+			}
+		},
+	},
 	{
 		name: "Create",
 		reqFunc: func(db *sql.DB) *http.Request {
@@ -285,10 +474,772 @@ true
 			return
 		},
 	},
+	{
+		name: "Listing sorted by sortable field",
+		reqFunc: func(db *sql.DB) *http.Request {
+			twoCustomersData.TruncatePut(db)
+			return httptest.NewRequest("GET", "/admin/my_customers?order_by=name_desc", nil)
+		},
+		pageMatch: func(body *bytes.Buffer, db *gorm.DB, t *testing.T) {
+			b := body.String()
+			anna, felix := strings.Index(b, ">Anna2</div>"), strings.Index(b, ">Felix1</div>")
+			if felix < 0 || felix > anna {
+				t.Error("Felix1 should be listed before Anna2", b)
+			}
+			if strings.Index(b, `"order_by":["name_asc"]`) < 0 {
+				t.Error("can't find sortable header", b)
+			}
+		},
+	},
+	{
+		name: "Listing ignores order by not in sortable fields",
+		reqFunc: func(db *sql.DB) *http.Request {
+			twoCustomersData.TruncatePut(db)
+			return httptest.NewRequest("GET", "/admin/my_customers?order_by=id_asc", nil)
+		},
+		pageMatch: func(body *bytes.Buffer, db *gorm.DB, t *testing.T) {
+			b := body.String()
+			anna, felix := strings.Index(b, ">Anna2</div>"), strings.Index(b, ">Felix1</div>")
+			if anna < 0 || anna > felix {
+				t.Error("Anna2 should be listed before Felix1 by default id desc", b)
+			}
+		},
+	},
+}
+
+func TestExport(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+
+	twoCustomersData.TruncatePut(rawDB)
+	emptyListingColumnsData.TruncatePut(rawDB)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers/export?format=csv&keyword=felix&order_by=name_asc", nil))
+	expected := "\xEF\xBB\xBF列表的名字,公司,ApprovalComment\nFelix1,0,\n"
+	if w.Body.String() != expected {
+		t.Errorf("expected %q, but was %q", expected, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers/export?format=xlsx&order_by=name_asc", nil))
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet []byte
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, _ := f.Open()
+		sheet, _ = ioutil.ReadAll(rc)
+		rc.Close()
+	}
+	if strings.Index(string(sheet), "Anna2") > strings.Index(string(sheet), "Felix1") ||
+		strings.Index(string(sheet), `<c t="n"><v>0</v></c>`) < 0 {
+		t.Error("wrong sheet", string(sheet))
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers/export?format=pdf", nil))
+	if w.Code != http.StatusNotFound {
+		t.Error("unsupported format should be not found", w.Code)
+	}
+
+	exportFormulaData.TruncatePut(rawDB)
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers/export?format=csv", nil))
+	expected = "\xEF\xBB\xBF列表的名字,公司,ApprovalComment\n\"'=HYPERLINK(\"\"http://x\"\")\",Acme,\n"
+	if w.Body.String() != expected {
+		t.Errorf("expected %q, but was %q", expected, w.Body.String())
+	}
+}
+
+var exportFormulaData = gofixtures.Data(gofixtures.Sql(`
+				insert into customers (id, name, company_id) values (11, '=HYPERLINK("http://x")', 1);
+				insert into companies (id, name) values (1, 'Acme');
+			`, []string{"customers", "companies"}))
+
+func TestImport(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	emptyCustomerData.TruncatePut(rawDB)
+
+	content := "name,company_id\nFelix Import,0\nAnna,0\nBob Smith,abc\n"
+	importReq := func(eventFuncID string, values map[string]string, file string) *testEventResponse {
+		body := bytes.NewBuffer(nil)
+		mw := multipart.NewWriter(body)
+		_ = mw.WriteField("__event_data__", fmt.Sprintf(`{"eventFuncId":{"id":%q,"params":[],"pushState":null},"event":{}}`, eventFuncID))
+		for k, v := range values {
+			_ = mw.WriteField(k, v)
+		}
+		if len(file) > 0 {
+			fw, _ := mw.CreateFormFile("ImportFile", "customers.csv")
+			_, _ = fw.Write([]byte(file))
+		}
+		_ = mw.Close()
+
+		r := httptest.NewRequest("POST", "/admin/my_customers?__execute_event__="+eventFuncID, body)
+		r.Header.Add("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+
+		var er testEventResponse
+		err := json.NewDecoder(w.Body).Decode(&er)
+		if err != nil || len(er.UpdatePortals) == 0 {
+			t.Fatalf("%v for: %s", err, w.Body.String())
+		}
+		return &er
+	}
+
+	er := importReq("presets_ImportUpload", nil, content)
+	tokenMatch := regexp.MustCompile(`value='([0-9a-f]+)' v-field-name='"ImportToken"'`).FindStringSubmatch(er.UpdatePortals[0].Body)
+	if tokenMatch == nil || strings.Contains(er.UpdatePortals[0].Body, "Felix Import") ||
+		!strings.Contains(er.UpdatePortals[0].Body, `v-field-name='"ImportColumn_0"'`) {
+		t.Fatal("mapping step should keep the file on server and show the columns", er.UpdatePortals[0].Body)
+	}
+
+	mapping := map[string]string{
+		"ImportToken":    tokenMatch[1],
+		"ImportColumn_0": "Name",
+		"ImportColumn_1": "CompanyID",
+	}
+	er = importReq("presets_ImportPreview", mapping, "")
+	if !strings.Contains(er.UpdatePortals[0].Body, "1 rows are valid, 2 rows have errors") ||
+		!strings.Contains(er.UpdatePortals[0].Body, "input more than 5 chars") ||
+		!strings.Contains(er.UpdatePortals[0].Body, "公司: Invalid value") {
+		t.Error("wrong preview", er.UpdatePortals[0].Body)
+	}
+	var count int64
+	db.Model(&examples2.Customer{}).Count(&count)
+	if count != 0 {
+		t.Error("preview should not save", count)
+	}
+
+	er = importReq("presets_DoImport", mapping, "")
+	if !strings.Contains(er.UpdatePortals[0].Body, "1 rows imported, 2 rows failed") ||
+		!strings.Contains(er.UpdatePortals[0].Body, "data:text/csv") {
+		t.Error("wrong import result", er.UpdatePortals[0].Body)
+	}
+	var customers []*examples2.Customer
+	db.Find(&customers)
+	if len(customers) != 1 || customers[0].Name != "Felix Import" {
+		t.Error("only the valid row should be imported", customers)
+	}
+
+	er = importReq("presets_DoImport", mapping, "")
+	if !strings.Contains(er.UpdatePortals[0].Body, "The uploaded file is expired") {
+		t.Error("the imported file should be deleted", er.UpdatePortals[0].Body)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	fiveProductsData.TruncatePut(rawDB)
+
+	cursorRe := regexp.MustCompile(`:disabled='(true|false)'( @click='[^']*"cursor":\["([^"]+)"\])?`)
+	listing := func(cursor string) (body string, prev string, next string) {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/products?cursor="+cursor, nil))
+		body = w.Body.String()
+		ms := cursorRe.FindAllStringSubmatch(body, -1)
+		if len(ms) < 2 {
+			t.Fatal("no cursor paginator", body)
+		}
+		return body, ms[len(ms)-2][3], ms[len(ms)-1][3]
+	}
+	names := func(body string) (r []string) {
+		for _, m := range regexp.MustCompile(`>(Product\d)</td>`).FindAllStringSubmatch(body, -1) {
+			r = append(r, m[1])
+		}
+		return
+	}
+
+	body, prev, next := listing("")
+	if fmt.Sprint(names(body)) != "[Product5 Product4 Product3]" || prev != "" || next == "" {
+		t.Fatal("wrong first page", names(body), prev, next)
+	}
+
+	body, prev, next = listing(next)
+	if fmt.Sprint(names(body)) != "[Product2 Product1]" || prev == "" || next != "" {
+		t.Fatal("wrong last page", names(body), prev, next)
+	}
+
+	body, prev, next = listing(prev)
+	if fmt.Sprint(names(body)) != "[Product5 Product4 Product3]" || prev != "" || next == "" {
+		t.Fatal("wrong previous page", names(body), prev, next)
+	}
+}
+
+func TestCursorPaginationNullValues(t *testing.T) {
+	db := ConnectDB()
+	rawDB, _ := db.DB()
+	emptyCustomerData.TruncatePut(rawDB)
+	// created with gorm that the times are saved in the format of the query args
+	day1 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	db.Create([]*examples2.Customer{
+		{ID: 1, Name: "C1", ApprovedAt: &day2},
+		{ID: 2, Name: "C2"},
+		{ID: 3, Name: "C3", ApprovedAt: &day1},
+		{ID: 4, Name: "C4"},
+		{ID: 5, Name: "C5", ApprovedAt: &day2},
+	})
+	op := gorm2op.DataOperator(db)
+
+	for _, desc := range []bool{false, true} {
+		cursor := &presets.SearchCursor{Columns: []*presets.CursorColumn{
+			{FieldName: "ApprovedAt", Column: "approved_at", Desc: desc, Nullable: true},
+			{FieldName: "ID", Column: "id", Desc: true},
+		}}
+		var names []string
+		for i := 0; i < 10; i++ {
+			objs, _, err := op.Search(&[]*examples2.Customer{}, &presets.SearchParams{PerPage: 2, Cursor: cursor}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := objs.([]*examples2.Customer)
+			if len(cs) == 0 {
+				break
+			}
+			for _, c := range cs {
+				names = append(names, c.Name)
+			}
+			last := cs[len(cs)-1]
+			cursor.Values = []interface{}{last.ApprovedAt, last.ID}
+		}
+
+		expected := "[C3 C5 C1 C4 C2]"
+		if desc {
+			expected = "[C4 C2 C5 C1 C3]"
+		}
+		if fmt.Sprint(names) != expected {
+			t.Errorf("desc %v: expected %s, but was %v", desc, expected, names)
+		}
+	}
+}
+
+func TestListingViews(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	emptyListingViewsData.TruncatePut(rawDB)
+
+	event := func(eventFuncID string, param string, values map[string]string) {
+		body := bytes.NewBuffer(nil)
+		mw := multipart.NewWriter(body)
+		_ = mw.WriteField("__event_data__", fmt.Sprintf(`{"eventFuncId":{"id":%q,"params":[%q],"pushState":null},"event":{}}`, eventFuncID, param))
+		for k, v := range values {
+			_ = mw.WriteField(k, v)
+		}
+		_ = mw.Close()
+		r := httptest.NewRequest("POST", "/admin/my_customers?__execute_event__="+eventFuncID, body)
+		r.Header.Add("Content-Type", mw.FormDataContentType())
+		p.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	event("presets_DoSaveView", "keyword=felix&page=2&order_by=name_desc", map[string]string{"ViewName": "Felix"})
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers?order_by=name_desc&keyword=felix", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `pushStateQuery({"keyword":["felix"],"order_by":["name_desc"]})`) ||
+		!strings.Contains(body, "<v-tabs :grow='true' :value='4' class='mb-3'>") {
+		t.Error("saved view should be listed as the active tab", body)
+	}
+
+	event("presets_DoDeleteView", "Felix", nil)
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers", nil))
+	if strings.Contains(w.Body.String(), `"keyword":["felix"]`) {
+		t.Error("deleted view should not be listed", w.Body.String())
+	}
+}
+
+func TestListingColumns(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	customerData.TruncatePut(rawDB)
+	emptyListingColumnsData.TruncatePut(rawDB)
+	// the saved columns would change the listing of the other tests
+	defer emptyListingColumnsData.TruncatePut(rawDB)
+
+	r := httptest.NewRequest("POST", "/admin/my_customers?__execute_event__=presets_UpdateListingColumns", strings.NewReader(`
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="__event_data__"
+
+{"eventFuncId":{"id":"presets_UpdateListingColumns","params":["Email,Name,NotAField"],"pushState":null},"event":{}}
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8--
+`))
+	r.Header.Add("Content-Type", `multipart/form-data; boundary=----WebKitFormBoundaryOv2oq9YJ8tIG3xJ8`)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, r)
+	if !strings.Contains(w.Body.String(), `"columns":["Email,Name"]`) {
+		t.Error("chosen columns should be pushed to url", w.Body.String())
+	}
+
+	// without columns in url, the saved columns of the user are used
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers", nil))
+	body := w.Body.String()
+	body = body[strings.Index(body, "<thead"):]
+	email, name := strings.Index(body, "<th>Email</th>"), strings.Index(body, "列表的名字")
+	if email < 0 || name < email || strings.Contains(body, "<th>ApprovalComment</th>") {
+		t.Error("wrong columns", body)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/my_customers/export?format=csv&columns=Name,ID,LanguageCode", nil))
+	expected := "\xEF\xBB\xBF列表的名字,ID\nFelix1,11\n"
+	if w.Body.String() != expected {
+		t.Errorf("expected %q, but was %q", expected, w.Body.String())
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	paymentsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/payments", nil))
+	body := strings.Join(strings.Fields(w.Body.String()), " ")
+
+	var expected = []string{
+		// group header rows with the subtotals, in the order of currency
+		`CurrencyCode: CNY</div>`,
+		`<span class='grey--text mr-1'>Sum</span> 300`,
+		`CurrencyCode: USD</div>`,
+		`<span class='grey--text mr-1'>Count</span> 3`,
+		`<span class='grey--text mr-1'>Sum</span> 175`,
+		`<span class='grey--text mr-1'>Avg</span> 58.33`,
+		// footer of all the records
+		`Total</div>`,
+		`<span class='grey--text mr-1'>Count</span> 4`,
+		`<span class='grey--text mr-1'>Sum</span> 475`,
+		`<span class='grey--text mr-1'>Avg</span> 118.75`,
+	}
+	from := 0
+	for _, e := range expected {
+		i := strings.Index(body[from:], e)
+		if i < 0 {
+			t.Fatalf("expected %q after %d in %s", e, from, body)
+		}
+		from += i + len(e)
+	}
+
+	// aggregates follow the search, not only the current page
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/payments?keyword=CNY", nil))
+	body = strings.Join(strings.Fields(w.Body.String()), " ")
+	if strings.Contains(body, "CurrencyCode: USD") ||
+		!strings.Contains(body, `<span class='grey--text mr-1'>Count</span> 1`) {
+		t.Error("aggregates should be of the searched records", body)
+	}
+
+	// data operators that are not Aggregators only group the records
+	p = presets.New().URIPrefix("/admin").DataOperator(searchOnlyOperator{gorm2op.DataOperator(db)})
+	p.Model(&examples2.Payment{}).
+		Listing("ID", "CurrencyCode", "Amount").
+		Aggregate("Amount", presets.AggregateSum).
+		GroupBy("CurrencyCode")
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/payments", nil))
+	body = strings.Join(strings.Fields(w.Body.String()), " ")
+	if !strings.Contains(body, "CurrencyCode: USD") || strings.Contains(body, ">Sum</span>") {
+		t.Error("aggregates should be hidden", body)
+	}
+}
+
+type searchOnlyOperator struct {
+	presets.DataOperator
+}
+
+func moveKanbanCardRequest(id string, column string) *http.Request {
+	r := httptest.NewRequest("POST", "/admin/tickets?__execute_event__=presets_MoveKanbanCard", strings.NewReader(fmt.Sprintf(`
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="__event_data__"
+
+{"eventFuncId":{"id":"presets_MoveKanbanCard","params":["%s"],"pushState":null},"event":{}}
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="kanbanCard"
+
+%s
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8--
+`, column, id)))
+	r.Header.Add("Content-Type", `multipart/form-data; boundary=----WebKitFormBoundaryOv2oq9YJ8tIG3xJ8`)
+	return r
+}
+
+func TestLiveUpdates(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	p.UserIDFunc(func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	})
+	rawDB, _ := db.DB()
+	ticketsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/tickets", nil))
+	if !strings.Contains(w.Body.String(), `<presets-live-updates url='/admin/tickets/changes' @change='vars.liveChanges++'>`) {
+		t.Error("expected live updates in listing", w.Body.String())
+	}
+
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/admin/tickets/changes", nil)
+	req.Header.Set("X-User", "dispatcher")
+	res, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected event stream, but was %s", res.Header.Get("Content-Type"))
+	}
+
+	// the changes of the user self are not sent
+	own := moveKanbanCardRequest("3", "done")
+	own.Header.Set("X-User", "dispatcher")
+	p.ServeHTTP(httptest.NewRecorder(), own)
+	other := moveKanbanCardRequest("1", "in_progress")
+	other.Header.Set("X-User", "agent")
+	p.ServeHTTP(httptest.NewRecorder(), other)
+
+	reader := bufio.NewReader(res.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	expected := []string{`event: change`, `data: {"model":"tickets","id":"1","type":"updated"}`}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %v, but was %v", expected, lines)
+	}
+}
+
+func TestKanban(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	ticketsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/tickets", nil))
+	body := strings.Join(strings.Fields(w.Body.String()), " ")
+	for _, e := range []string{
+		`portal-name='kanbanBoard'`,
+		`Open <v-chip :small='true' class='ml-2'>2</v-chip>`,
+		`In Progress <v-chip :small='true' class='ml-2'>1</v-chip>`,
+		`Done <v-chip :small='true' class='ml-2'>0</v-chip>`,
+		`@dragstart='vars.kanbanCard = "1"'`,
+	} {
+		if !strings.Contains(body, e) {
+			t.Fatalf("expected %q in %s", e, body)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/tickets?layout=table", nil))
+	if strings.Contains(w.Body.String(), "kanbanBoard") {
+		t.Error("table layout should not show the board")
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, moveKanbanCardRequest("1", "done"))
+	if !strings.Contains(w.Body.String(), `"name":"kanbanBoard"`) {
+		t.Error("board should be updated", w.Body.String())
+	}
+	var status string
+	db.Raw("SELECT status FROM tickets WHERE id = 1").Scan(&status)
+	if status != "done" {
+		t.Errorf("expected status done, but was %q", status)
+	}
+
+	// the validator of the editing form rejects it
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, moveKanbanCardRequest("2", "in_progress"))
+	if !strings.Contains(w.Body.String(), "assign the ticket before starting it") {
+		t.Error("validation error should show", w.Body.String())
+	}
+	db.Raw("SELECT status FROM tickets WHERE id = 2").Scan(&status)
+	if status != "open" {
+		t.Errorf("expected status open, but was %q", status)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	// the times of the fixtures are in UTC+8
+	p.TimeLocationFunc(func(r *http.Request) *time.Location {
+		return time.FixedZone("UTC+8", 8*60*60)
+	})
+	rawDB, _ := db.DB()
+	appointmentsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/appointments?calendarDate=2026-10-12", nil))
+	body := w.Body.String()
+	for _, e := range []string{
+		`portal-name='calendar'`,
+		`October 2026`,
+		`{"id":"1","name":"Dentist","start":"2026-10-05 10:00","end":"2026-10-05 11:30","timed":true}`,
+		`{"id":"2","name":"Haircut","start":"2026-10-20 15:00","end":"2026-10-20 16:00","timed":true}`,
+	} {
+		if !strings.Contains(body, e) {
+			t.Fatalf("expected %q in %s", e, body)
+		}
+	}
+	if strings.Contains(body, "Next Month") {
+		t.Error("events out of the month should not show")
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/appointments?calendarDate=2026-10-05&calendarType=day", nil))
+	if body = w.Body.String(); !strings.Contains(body, "Dentist") || strings.Contains(body, "Haircut") {
+		t.Error("day view should only show the events of the day", body)
+	}
+
+	// dragging the event from 10:05 to 14:20 of the next day
+	r := httptest.NewRequest("POST", "/admin/appointments?calendarDate=2026-10-12&__execute_event__=presets_RescheduleCalendarEvent", strings.NewReader(`
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="__event_data__"
+
+{"eventFuncId":{"id":"presets_RescheduleCalendarEvent","params":[],"pushState":null},"event":{}}
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="calendarEvent"
+
+1
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="calendarFrom"
+
+2026-10-05 10:05
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="calendarTo"
+
+2026-10-06 14:20
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8--
+`))
+	r.Header.Add("Content-Type", `multipart/form-data; boundary=----WebKitFormBoundaryOv2oq9YJ8tIG3xJ8`)
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, r)
+	if !strings.Contains(w.Body.String(), `\"start\":\"2026-10-06 14:15\",\"end\":\"2026-10-06 15:45\"`) {
+		t.Error("event should be rescheduled", w.Body.String())
+	}
+}
+
+func TestFullTextSearch(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	if db.Exec("SELECT rowid FROM tickets_fts LIMIT 0").Error != nil {
+		t.Skip("sqlite is built without fts5, run the tests with -tags sqlite_fts5")
+	}
+	rawDB, _ := db.DB()
+	fullTextTicketsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/tickets?layout=table&keyword=slow+export", nil))
+	body := strings.Join(strings.Fields(w.Body.String()), " ")
+	first := strings.Index(body, "<mark>Slow</mark> <mark>export</mark>")
+	second := strings.Index(body, "<mark>Export</mark> fails sometimes")
+	if first < 0 || second < 0 || first > second {
+		t.Error("expected ranked by relevance with highlighted keyword", body)
+	}
+	if strings.Contains(body, "Typo in footer") {
+		t.Error("expected only matching tickets", body)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/tickets?layout=table&keyword=anna", nil))
+	body = w.Body.String()
+	if !strings.Contains(body, "Typo in footer") || strings.Contains(body, "Slow export") {
+		t.Error("expected matched by assignee", body)
+	}
+
+	// the keyword of only spaces searches nothing
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/tickets?layout=table&keyword=%20", nil))
+	body = w.Body.String()
+	if !strings.Contains(body, "Typo in footer") || !strings.Contains(body, "Slow export") {
+		t.Error("expected all tickets", body)
+	}
+	_, count, err := gorm2op.DataOperator(db).Search(&[]*examples2.Ticket{}, &presets.SearchParams{
+		KeywordColumns: []string{"title"},
+		Keyword:        " ",
+		FullTextSearch: presets.SQLiteFullText(),
+		RankByKeyword:  true,
+	}, nil)
+	if err != nil || count != 3 {
+		t.Error("expected all tickets searched", err, count)
+	}
+}
+
+func TestGlobalSearch(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	twoCustomersData.TruncatePut(rawDB)
+	ticketsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/search?keyword=felix", nil))
+	body := w.Body.String()
+	for _, e := range []string{
+		"Felix1",
+		"Login fails",
+		`eventFunc("presets_DrawerEdit", "1").url("/admin/tickets")`,
+		`pushStateURL("/admin/tickets?keyword=felix")`,
+	} {
+		if !strings.Contains(body, e) {
+			t.Errorf("expected %q in %s", e, body)
+		}
+	}
+	if strings.Contains(body, "Anna2") || strings.Contains(body, "Typo in footer") {
+		t.Error("expected only matching records", body)
+	}
+
+	p.Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Denied).ToDo(presets.PermList).On("*tickets*"),
+	))
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/search?keyword=felix", nil))
+	body = w.Body.String()
+	if !strings.Contains(body, "Felix1") || strings.Contains(body, "Login fails") {
+		t.Error("expected tickets skipped without list permission", body)
+	}
+
+	// the models failed to search are skipped
+	p = presets.New().URIPrefix("/admin").DataOperator(gorm2op.DataOperator(db))
+	p.Model(&examples2.Customer{}).Listing("Name").SearchColumns("name")
+	p.Model(&examples2.Ticket{}).Listing("Title").SearchColumns("title").
+		Searcher(func(model interface{}, params *presets.SearchParams, ctx *web.EventContext) (r interface{}, totalCount int, err error) {
+			return nil, 0, errors.New("no full-text index")
+		})
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/search?keyword=felix", nil))
+	body = w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "Felix1") {
+		t.Error("expected the other models searched", w.Code, body)
+	}
+}
+
+func eventRequest(path string, eventFuncID string, params string, fields ...string) *http.Request {
+	return eventValueRequest(path, eventFuncID, params, "", fields...)
+}
+
+func eventValueRequest(path string, eventFuncID string, params string, value string, fields ...string) *http.Request {
+	body := fmt.Sprintf(`
+------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="__event_data__"
+
+{"eventFuncId":{"id":"%s","params":%s,"pushState":null},"event":{"value":%q}}
+`, eventFuncID, params, value)
+	for i := 0; i < len(fields); i += 2 {
+		body += fmt.Sprintf(`------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8
+Content-Disposition: form-data; name="%s"
+
+%s
+`, fields[i], fields[i+1])
+	}
+	body += "------WebKitFormBoundaryOv2oq9YJ8tIG3xJ8--\n"
+
+	r := httptest.NewRequest("POST", path+"?__execute_event__="+eventFuncID, strings.NewReader(body))
+	r.Header.Add("Content-Type", `multipart/form-data; boundary=----WebKitFormBoundaryOv2oq9YJ8tIG3xJ8`)
+	return r
+}
+
+func TestTree(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	categoriesData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/categories", nil))
+	body := w.Body.String()
+	for _, e := range []string{
+		`portal-name='tree'`,
+		`{"id":"1","name":"Root A","movable":true,"children":[]}`,
+		`{"id":"4","name":"Root B","movable":true,"children":null}`,
+	} {
+		if !strings.Contains(body, e) {
+			t.Fatalf("expected %q in %s", e, body)
+		}
+	}
+	if strings.Contains(body, "Child A1") {
+		t.Error("children should be loaded when opened")
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/categories", "presets_LoadTreeChildren", `["1"]`))
+	if !strings.Contains(w.Body.String(), `"data":[{"id":"2","name":"Child A1","movable":true,"children":[]}]`) {
+		t.Error("wrong children", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/categories", "presets_MoveTreeNode", `[]`, "treeNode", "1", "treeParent", "3"))
+	if !strings.Contains(w.Body.String(), "move it under itself or its descendants") {
+		t.Error("cycles should be blocked", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/categories", "presets_MoveTreeNode", `[]`, "treeNode", "3", "treeParent", ""))
+	var parentID int
+	db.Raw("SELECT parent_id FROM categories WHERE id = 3").Scan(&parentID)
+	if parentID != 0 {
+		t.Errorf("expected moved to root, but parent was %d", parentID)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/categories", "presets_DrawerNew", `[""]`, "__TreeParent", "4"))
+	if !strings.Contains(w.Body.String(), `type='hidden' value='4' v-field-name='\"__TreeParent\"'`) {
+		t.Error("expected the parent in the form", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/categories", "presets_Update", `[""]`, "Name", "Child B1", "__TreeParent", "4"))
+	db.Raw("SELECT parent_id FROM categories WHERE name = 'Child B1'").Scan(&parentID)
+	if parentID != 4 {
+		t.Errorf("expected created under 4, but parent was %d", parentID)
+	}
+
+	// data operators that are not Aggregators can't count the children, so that all the nodes can be opened
+	p = presets.New().URIPrefix("/admin").DataOperator(searchOnlyOperator{gorm2op.DataOperator(db)})
+	p.Model(&examples2.Category{}).Listing("Name").Tree("ParentID").Default(true)
+	categoriesData.TruncatePut(rawDB)
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/categories", nil))
+	body = w.Body.String()
+	for _, e := range []string{
+		`{"id":"1","name":"Root A","movable":true,"children":[]}`,
+		`{"id":"4","name":"Root B","movable":true,"children":[]}`,
+	} {
+		if !strings.Contains(body, e) {
+			t.Errorf("expected %q in %s", e, body)
+		}
+	}
 }
 
 func ConnectDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("/tmp/my_integration.db"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open("/tmp/my_integration.db"), &gorm.Config{
+		// sqlite can't add the foreign keys of has-many fields like Customer.CreditCards to the existing tables
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
 	if err != nil {
 		panic(err)
 	}
@@ -342,3 +1293,682 @@ func TestAll(t *testing.T) {
 
 	}
 }
+
+func TestValidation(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	ticketsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/tickets", "presets_DrawerNew", `[""]`))
+	if !strings.Contains(w.Body.String(), `Title *`) {
+		t.Error("expected required field marked", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/tickets", "presets_Update", `["","1"]`, "Title", " ", "Assignee", "Felix", "Status", "closed"))
+	body := w.Body.String()
+	for _, m := range []string{"Required", "Must be one of open, in_progress, done"} {
+		if !strings.Contains(body, m) {
+			t.Error("expected validation message", m, body)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/tickets", "presets_Update", `["","1"]`, "Title", strings.Repeat("x", 101), "Assignee", "Felix", "Status", "open"))
+	if !strings.Contains(w.Body.String(), "Must be at most 100 characters") {
+		t.Error("expected max length message", w.Body.String())
+	}
+
+	var title string
+	db.Raw("SELECT title FROM tickets WHERE id = 1").Scan(&title)
+	if title != "Login fails" {
+		t.Error("expected invalid ticket not saved", title)
+	}
+
+	// the validate tag of other validators is ignored, and the invalid rules are skipped
+	p = presets.New().URIPrefix("/admin").DataOperator(gorm2op.DataOperator(db))
+	p.Model(&taggedTicket{}).URIName("tickets").Editing("Title")
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/tickets", "presets_Update", `["","1"]`, "Title", ""))
+	if !strings.Contains(w.Body.String(), "Required") {
+		t.Error("expected validation message", w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/tickets", "presets_Update", `["","1"]`, "Title", "Login fails again"))
+	var count int
+	db.Raw("SELECT count(*) FROM tickets WHERE title = 'Login fails again'").Scan(&count)
+	if count != 1 {
+		t.Error("expected ticket created", w.Body.String())
+	}
+}
+
+type taggedTicket struct {
+	ID    int
+	Title string `validate:"omitempty,uuid" presets:"required,uuid,max=abc"`
+}
+
+func (taggedTicket) TableName() string {
+	return "tickets"
+}
+
+func TestBelongsTo(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	paymentsData.TruncatePut(rawDB)
+	twoCustomersData.TruncatePut(rawDB)
+
+	var customerQueries int
+	_ = db.Callback().Query().Register("test:count_customer_queries", func(tx *gorm.DB) {
+		if _, isCount := tx.Statement.Dest.(*int64); tx.Statement.Table == "customers" && !isCount {
+			customerQueries++
+		}
+	})
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/payments", nil))
+	if !strings.Contains(w.Body.String(), `pushStateURL("/admin/my_customers/11").go()'>Felix1</a>`) {
+		t.Error("expected link to the customer", w.Body.String())
+	}
+	if customerQueries != 1 {
+		t.Error("expected the customers of the page fetched at once, but queried", customerQueries)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/payments", "presets_DrawerEdit", `["1"]`))
+	body := w.Body.String()
+	for _, e := range []string{`field-name='CustomerID'`, `:selected-items='[{\"text\":\"Felix1\",\"value\":11}]'`} {
+		if !strings.Contains(body, e) {
+			t.Error("expected autocomplete of customers", e, body)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventValueRequest("/admin/payments", "presets_BelongsToOptions", `["CustomerID"]`, "anna"))
+	body = w.Body.String()
+	if !strings.Contains(body, `{"text":"Anna2","value":12}`) || strings.Contains(body, "Felix1") {
+		t.Error("expected customers searched by keyword", body)
+	}
+
+	p.Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Denied).ToDo(presets.PermList).On("*my_customers*"),
+	))
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventValueRequest("/admin/payments", "presets_BelongsToOptions", `["CustomerID"]`, "anna"))
+	if strings.Contains(w.Body.String(), "Anna2") {
+		t.Error("expected customers not searched without list permission", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/payments", nil))
+	if body = w.Body.String(); strings.Contains(body, "Felix1") || !strings.Contains(body, `"1").go()'>11</td>`) {
+		t.Error("expected the id of the customer without list permission", body)
+	}
+
+	// the records are found by the foreign keys, and linked with the slugs
+	p = presets.New().URIPrefix("/admin").DataOperator(gorm2op.DataOperator(db))
+	p.Model(&Customer{})
+	p.Model(&examples2.Payment{}).Listing("ID", "CustomerID")
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/payments", nil))
+	if !strings.Contains(w.Body.String(), `eventFunc("presets_DrawerEdit", "customer-11").url("/admin/customers").go()'>Felix1</a>`) {
+		t.Error("expected link to the customer with the slug", w.Body.String())
+	}
+}
+
+// Customer has the slugs different from the ids, it's named like the model that the CustomerID fields refer to
+type Customer struct {
+	ID   int
+	Name string
+}
+
+func (c *Customer) PageTitle() string {
+	return c.Name
+}
+
+func (c *Customer) PrimarySlug() string {
+	return fmt.Sprintf("customer-%d", c.ID)
+}
+
+func (c *Customer) PrimaryColumnValuesBySlug(slug string) [][]string {
+	return [][]string{{"id", strings.TrimPrefix(slug, "customer-")}}
+}
+
+func TestHasMany(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	customerCreditCardsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_DrawerEdit", `["11"]`))
+	if !strings.Contains(w.Body.String(), `CreditCards[1].Number`) {
+		t.Error("expected rows of credit cards", w.Body.String())
+	}
+
+	rows := []string{"Name", "Felix1", "__HasManyRows_CreditCards", "2",
+		"CreditCards[0].ID", "2", "CreditCards[0].Number", "5501",
+		"CreditCards[1].ID", "0", "CreditCards[1].Number", ""}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_AddHasManyRow", `["11","CreditCards"]`, rows...))
+	if !strings.Contains(w.Body.String(), `CreditCards[2].Number`) || strings.Contains(w.Body.String(), "Required") {
+		t.Error("expected a new row without validation errors", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_Update", `["11"]`, rows...))
+	if !strings.Contains(w.Body.String(), "Required") {
+		t.Error("expected validation error of the new row", w.Body.String())
+	}
+	var count int64
+	db.Model(&examples2.CreditCard{}).Where("customer_id = 11").Count(&count)
+	if count != 2 {
+		t.Error("expected nothing saved with validation errors", count)
+	}
+
+	rows[len(rows)-1] = "6011"
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_Update", `["11"]`, rows...))
+	var cards []*examples2.CreditCard
+	db.Where("customer_id = 11").Order("id").Find(&cards)
+	if len(cards) != 2 || cards[0].ID != 2 || cards[0].Number != "5501" || cards[1].Number != "6011" {
+		t.Error("expected the removed card deleted and the others saved", cards)
+	}
+
+	// the children are only loaded and saved for the has-many fields of the form
+	op := gorm2op.DataOperator(db)
+	cu, _ := op.Fetch(&examples2.Customer{}, "11", nil)
+	if cu.(*examples2.Customer).CreditCards != nil {
+		t.Error("expected the cards not loaded", cu)
+	}
+	cu.(*examples2.Customer).CreditCards = []*examples2.CreditCard{}
+	if err := op.Save(cu, "11", nil); err != nil {
+		t.Fatal(err)
+	}
+	p = presets.New().URIPrefix("/admin").DataOperator(op)
+	p.Model(&examples2.Customer{}).URIName("my_customers").Editing("Name")
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_Update", `["11"]`, "Name", "Felix Updated"))
+	cards = nil
+	db.Where("customer_id = 11").Find(&cards)
+	var name string
+	db.Raw("SELECT name FROM customers WHERE id = 11").Scan(&name)
+	if len(cards) != 2 || name != "Felix Updated" {
+		t.Error("expected the customer saved without deleting the cards", name, cards)
+	}
+}
+
+func TestManyToMany(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	productTagsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/products", nil))
+	if !strings.Contains(w.Body.String(), `Laptop</v-chip>`) || !strings.Contains(w.Body.String(), `Sale</v-chip>`) {
+		t.Error("expected chips of the tags", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_DrawerEdit", `["12"]`))
+	if !strings.Contains(w.Body.String(), `{\"text\":\"Sale\",\"value\":2}`) {
+		t.Error("expected the tags selected", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventValueRequest("/admin/products", "presets_ManyToManyOptions", `["Tags"]`, "Ne"))
+	if !strings.Contains(w.Body.String(), `"text":"New"`) || strings.Contains(w.Body.String(), `"text":"Sale"`) {
+		t.Error("expected the tags matching the keyword", w.Body.String())
+	}
+
+	for _, field := range []string{"Name", "Unknown"} {
+		w = httptest.NewRecorder()
+		p.ServeHTTP(w, eventValueRequest("/admin/products", "presets_ManyToManyOptions", fmt.Sprintf(`[%q]`, field), "Ne"))
+		if !strings.Contains(w.Body.String(), `"data":[]`) {
+			t.Error("expected no options of the field that is not many-to-many", field, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`,
+		"Name", "Product 1", "__ManyToMany_Tags", "1", "Tags", "1", "Tags", "3"))
+	var tagIDs []int
+	db.Table("product_tags").Where("product_id = 12").Order("tag_id").Pluck("tag_id", &tagIDs)
+	if fmt.Sprint(tagIDs) != "[1 3]" {
+		t.Error("expected the tags replaced", tagIDs)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`, "Name", "Product 2"))
+	tagIDs = nil
+	db.Table("product_tags").Where("product_id = 12").Order("tag_id").Pluck("tag_id", &tagIDs)
+	if fmt.Sprint(tagIDs) != "[1 3]" {
+		t.Error("expected the tags kept if they are not in the form", tagIDs)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`, "Name", "Product 3", "__ManyToMany_Tags", "1"))
+	var count int64
+	db.Table("product_tags").Where("product_id = 12").Count(&count)
+	if count != 0 {
+		t.Error("expected the tags cleared", count)
+	}
+
+	// the tags are loaded with the product, and the chosen ones are searched at once
+	var tagQueries int
+	_ = db.Callback().Query().Register("test:count_tag_queries", func(tx *gorm.DB) {
+		if tx.Statement.Table == "tags" {
+			tagQueries++
+		}
+	})
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`,
+		"Name", "Product 4", "__ManyToMany_Tags", "1", "Tags", "1", "Tags", "2", "Tags", "3"))
+	if tagQueries != 2 {
+		t.Error("expected the tags loaded with the product and searched at once, but queried", tagQueries)
+	}
+
+	p.Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Denied).ToDo(presets.PermList).On("*tags*"),
+	))
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`, "Name", "Product 5", "__ManyToMany_Tags", "1", "Tags", "1"))
+	count = 0
+	db.Table("product_tags").Where("product_id = 12").Count(&count)
+	if count != 3 {
+		t.Error("expected the tags not chosen without list permission", count)
+	}
+
+	// the chosen records are found by the primary field values of the options, not the slugs
+	productTagsData.TruncatePut(rawDB)
+	p = presets.New().URIPrefix("/admin").DataOperator(gorm2op.DataOperator(db))
+	p.ManyToMany([]*Tag{})
+	p.Model(&Tag{})
+	p.Model(&Product{}).Editing("Name", "Tags")
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`, "Name", "Product 6", "__ManyToMany_Tags", "1", "Tags", "3"))
+	tagIDs = nil
+	db.Table("product_tags").Where("product_id = 12").Pluck("tag_id", &tagIDs)
+	if fmt.Sprint(tagIDs) != "[3]" {
+		t.Error("expected the tags with slugs chosen", tagIDs, w.Body.String())
+	}
+}
+
+type Product struct {
+	ID   int
+	Name string
+	Tags []*Tag `gorm:"many2many:product_tags"`
+}
+
+// Tag has the slugs different from the ids, that are the values of the options
+type Tag struct {
+	ID   int
+	Name string
+}
+
+func (t *Tag) PrimarySlug() string {
+	return fmt.Sprintf("tag-%d", t.ID)
+}
+
+func (t *Tag) PrimaryColumnValuesBySlug(slug string) [][]string {
+	return [][]string{{"id", strings.TrimPrefix(slug, "tag-")}}
+}
+
+func TestAttachment(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	productData.TruncatePut(rawDB)
+
+	upload := func(filename string, file string) string {
+		body := bytes.NewBuffer(nil)
+		mw := multipart.NewWriter(body)
+		_ = mw.WriteField("__event_data__", `{"eventFuncId":{"id":"presets_Update","params":["12"],"pushState":null},"event":{}}`)
+		_ = mw.WriteField("Name", "Product 1")
+		fw, _ := mw.CreateFormFile("__Attachment_Manual", filename)
+		_, _ = fw.Write([]byte(file))
+		_ = mw.Close()
+
+		r := httptest.NewRequest("POST", "/admin/products?__execute_event__=presets_Update", body)
+		r.Header.Add("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+
+		var u examples2.Product
+		db.First(&u, 12)
+		if u.Manual.FileName != filename || u.Manual.Size != int64(len(file)) || !strings.HasPrefix(u.Manual.Key, "products/") {
+			t.Error("expected the attachment saved", u.Manual)
+		}
+		return u.Manual.Key
+	}
+	download := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/products/attachments?id=12&field=Manual", nil))
+		return w
+	}
+
+	upload("manual.txt", "read me first")
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/products", nil))
+	if !strings.Contains(w.Body.String(), `href='/admin/products/attachments?field=Manual&id=12'`) {
+		t.Error("expected the link of the attachment", w.Body.String())
+	}
+	w = download()
+	if w.Body.String() != "read me first" || !strings.Contains(w.Header().Get("Content-Disposition"), `filename="manual.txt"`) ||
+		w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("expected the file downloaded", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/products/attachments?id=12&field=OwnerName", nil))
+	if w.Code != http.StatusNotFound {
+		t.Error("expected only attachments downloaded", w.Code)
+	}
+
+	// a stand-in of s3 that stores the objects by the paths
+	objects := map[string][]byte{}
+	s3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = ioutil.ReadAll(r.Body)
+		case http.MethodGet:
+			b, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(b)
+		}
+	}))
+	defer s3.Close()
+	p.AttachmentStorage(storage.S3("my-bucket", "us-east-1").Endpoint(s3.URL).Credentials("AKID", "secret"))
+
+	key := upload("manual.txt", "read me on s3")
+	if string(objects["/my-bucket/"+key]) != "read me on s3" {
+		t.Error("expected the file put to s3", objects)
+	}
+	w = download()
+	if w.Body.String() != "read me on s3" {
+		t.Error("expected the file downloaded from s3", w.Code, w.Body.String())
+	}
+
+	// the extensions that are not the same in urls are dropped
+	key = upload("manual.b?y%zz", "read me without extension")
+	if strings.Contains(key, ".") || string(objects["/my-bucket/"+key]) != "read me without extension" {
+		t.Error("expected the file put without the extension", key, objects)
+	}
+
+	p.Permission(perm.New().Policies(
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Allowed).ToDo(perm.Anything).On(perm.Anything),
+		perm.PolicyFor(perm.Anybody).WhoAre(perm.Denied).ToDo(presets.PermGet).On("*products*"),
+	))
+	w = download()
+	if w.Code != http.StatusForbidden {
+		t.Error("expected the file not downloaded without get permission", w.Code)
+	}
+}
+
+func TestTimeFields(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	// the times of the fixtures are in UTC+8
+	cst := time.FixedZone("UTC+8", 8*60*60)
+	p.TimeLocationFunc(func(r *http.Request) *time.Location {
+		return cst
+	})
+	rawDB, _ := db.DB()
+	appointmentsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/appointments", "presets_DrawerEdit", `["1"]`))
+	if !strings.Contains(w.Body.String(), `\"timeField_StartAt\":\"2026-10-05 10:00\"`) {
+		t.Error("expected the time in the picker", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/appointments", "presets_Update", `["1"]`,
+		"Title", "Dentist", "__Time_StartAt", "2026-10-06 09:30", "__Time_EndAt", ""))
+	var a examples2.Appointment
+	db.First(&a, 1)
+	if !a.StartAt.Equal(time.Date(2026, 10, 6, 9, 30, 0, 0, cst)) || a.EndAt != nil {
+		t.Error("expected the time parsed and the empty pointer saved as null", a.StartAt, a.EndAt)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/appointments", "presets_Update", `["1"]`, "Title", "Dentist", "__Time_StartAt", "tomorrow"))
+	if !strings.Contains(w.Body.String(), "Invalid date or time") {
+		t.Error("expected the invalid time error", w.Body.String())
+	}
+
+	utc, _ := time.LoadLocation("UTC")
+	p.TimeLocationFunc(func(r *http.Request) *time.Location {
+		return utc
+	})
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/appointments", "presets_Update", `["2"]`, "Title", "Haircut", "__Time_EndAt", "2026-10-20 08:00"))
+	a = examples2.Appointment{}
+	db.First(&a, 2)
+	if a.EndAt == nil || !a.EndAt.Equal(time.Date(2026, 10, 20, 8, 0, 0, 0, utc)) || !a.StartAt.Equal(time.Date(2026, 10, 20, 15, 0, 0, 0, cst)) {
+		t.Error("expected the time parsed in the time zone of the user, and the time not in the form kept", a.StartAt, a.EndAt)
+	}
+
+	// the time of the day keeps the date of the field
+	p = presets.New().URIPrefix("/admin").DataOperator(gorm2op.DataOperator(db)).TimeLocationFunc(func(r *http.Request) *time.Location {
+		return cst
+	})
+	p.Model(&examples2.Appointment{}).Editing("StartAt").Field("StartAt").TimeKind(presets.TimeKindTime)
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/appointments", "presets_Update", `["2"]`, "__Time_StartAt", "09:45"))
+	a = examples2.Appointment{}
+	db.First(&a, 2)
+	if !a.StartAt.Equal(time.Date(2026, 10, 20, 9, 45, 0, 0, cst)) {
+		t.Error("expected the date kept", a.StartAt, w.Body.String())
+	}
+}
+
+func TestSelectable(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	customerCreditCardsData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/credit-cards", "presets_DrawerEdit", `["1"]`))
+	if !strings.Contains(w.Body.String(), `{\"text\":\"American Express\",\"value\":\"amex\"}`) {
+		t.Error("expected the options of the card type", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/credit-cards", "presets_Update", `["1","11"]`, "Type", "discover"))
+	if !strings.Contains(w.Body.String(), "Must be one of Visa, Mastercard, American Express") {
+		t.Error("expected the unknown card type rejected", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/credit-cards", "presets_Update", `["1","11"]`, "Type", "amex"))
+	var card examples2.CreditCard
+	db.First(&card, 1)
+	if card.Type != "amex" {
+		t.Error("expected the card type saved", card.Type)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/credit-cards", nil))
+	if !strings.Contains(w.Body.String(), ">American Express</td>") {
+		t.Error("expected the text of the card type in the listing", w.Body.String())
+	}
+}
+
+func TestRichText(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	productData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`,
+		"Name", "Product 1", "Description", `<h2>Fast</h2><p onclick="alert(1)">Good <b>laptop</b></p><script>alert(2)</script>`))
+	var u examples2.Product
+	db.First(&u, 12)
+	if u.Description != `<h2>Fast</h2><p>Good <b>laptop</b></p>` {
+		t.Error("expected the description sanitized", u.Description)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_Update", `["12"]`, "Name", "Product 2"))
+	u = examples2.Product{}
+	db.First(&u, 12)
+	if u.Description != `<h2>Fast</h2><p>Good <b>laptop</b></p>` {
+		t.Error("expected the description kept if it's not in the form", u.Description)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/products", nil))
+	if !strings.Contains(w.Body.String(), `>Fast Good laptop</td>`) {
+		t.Error("expected the plain text of the description in the listing", w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `<script src="/admin/extra/tiptap.js"></script>`) {
+		t.Error("expected the tiptap js registered", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/products", "presets_DrawerEdit", `["12"]`))
+	if !strings.Contains(w.Body.String(), `\u003ctiptap-editor :field-name='\"Description\"'`) {
+		t.Error("expected the tiptap editor", w.Body.String())
+	}
+}
+
+func TestLockVersion(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	customerData.TruncatePut(rawDB)
+
+	lockVersion := func(body string) string {
+		m := regexp.MustCompile(`value='([^']*)' v-field-name='\\"__LockVersion\\"'`).FindStringSubmatch(body)
+		if m == nil {
+			t.Fatal("expected the lock version in the form", body)
+		}
+		return m[1]
+	}
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_DrawerEdit", `["11"]`))
+	opened := lockVersion(w.Body.String())
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_Update", `["11"]`, "Name", "Felix Updated", "__LockVersion", opened))
+	var u examples2.Customer
+	db.First(&u, 11)
+	if u.Name != "Felix Updated" {
+		t.Error("expected the customer updated", u.Name, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_Update", `["11"]`, "Name", "Felix Again", "__LockVersion", opened))
+	u = examples2.Customer{}
+	db.First(&u, 11)
+	if u.Name != "Felix Updated" {
+		t.Error("expected the stale update rejected", u.Name)
+	}
+	if !strings.Contains(w.Body.String(), "updated by someone else") || !strings.Contains(w.Body.String(), "Saved value: Felix Updated") {
+		t.Error("expected the conflict with the saved name", w.Body.String())
+	}
+	saved := lockVersion(w.Body.String())
+	if saved == opened {
+		t.Error("expected the form carries the saved version", saved)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_Update", `["11"]`, "Name", "Felix Again", "__LockVersion", saved))
+	u = examples2.Customer{}
+	db.First(&u, 11)
+	if u.Name != "Felix Again" {
+		t.Error("expected the update overwrites after the conflict", u.Name, w.Body.String())
+	}
+
+	// the other updates like inline edits change the version too
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/my_customers", "presets_DoInlineEdit", `["11","Name"]`, "Name", "Felix Inline"))
+	var inlined examples2.Customer
+	db.First(&inlined, 11)
+	if inlined.Name != "Felix Inline" || inlined.UpdatedAt.Equal(u.UpdatedAt) {
+		t.Error("expected the version changed by the inline edit", u.UpdatedAt, inlined.UpdatedAt, w.Body.String())
+	}
+
+	// the data operator only updates the record of the version it's fetched with
+	u.Name = "Felix Stale"
+	err := gorm2op.DataOperator(db).SaveWithParams(&u, "11", &presets.SaveParams{
+		Fields:      []string{"Name"},
+		LockVersion: &presets.LockVersionParams{Field: "UpdatedAt", Version: u.UpdatedAt},
+	}, nil)
+	inlined = examples2.Customer{}
+	db.First(&inlined, 11)
+	if err != presets.ErrLockConflict || inlined.Name != "Felix Inline" {
+		t.Error("expected the stale version not saved", err, inlined.Name)
+	}
+
+	// the misspelled version field fails with the message
+	func() {
+		defer func() {
+			if r := recover(); !strings.Contains(fmt.Sprint(r), "must be an integer or time.Time") {
+				t.Error("expected the version field checked", r)
+			}
+		}()
+		presets.New().Model(&examples2.Customer{}).Editing().LockVersion("UpdateAt")
+	}()
+}
+
+func TestEditingFullPage(t *testing.T) {
+	db := ConnectDB()
+	p := examples2.Preset1(db)
+	rawDB, _ := db.DB()
+	companiesData.TruncatePut(rawDB)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/companies", "presets_DrawerEdit", `["2"]`))
+	if !strings.Contains(w.Body.String(), `"url":"/admin/companies/2/edit"`) {
+		t.Error("expected the editing page pushed instead of the drawer", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/companies/2/edit", nil))
+	if !strings.Contains(w.Body.String(), `<title>Editing Company 2`) ||
+		!strings.Contains(w.Body.String(), `portal-name='editingPagePortalName'`) ||
+		!strings.Contains(w.Body.String(), `v-field-name='"Name"' label='Name *' :value='"Acme"'`) {
+		t.Error("expected the editing page of the company", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/companies", "presets_Update", `["2"]`, "Name", " "))
+	if !strings.Contains(w.Body.String(), `"name":"editingPagePortalName"`) || !strings.Contains(w.Body.String(), "Required") {
+		t.Error("expected the page form shown again with the errors", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, eventRequest("/admin/companies", "presets_Update", `["2"]`, "Name", "Acme Inc"))
+	if !strings.Contains(w.Body.String(), `"url":"/admin/companies"`) {
+		t.Error("expected back to the listing after it's saved", w.Body.String())
+	}
+	var company examples2.Company
+	db.First(&company, 2)
+	if company.Name != "Acme Inc" {
+		t.Error("expected the company saved", company)
+	}
+
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/admin/companies/new", nil))
+	if !strings.Contains(w.Body.String(), `<title>New Company`) {
+		t.Error("expected the creating page of the company", w.Body.String())
+	}
+}
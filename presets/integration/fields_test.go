@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"context"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -64,7 +65,9 @@ func TestFields(t *testing.T) {
 			FoundedAt: time.Unix(1567048169, 0),
 		},
 	}
-	mb := presets.New().Model(&User{})
+	mb := presets.New().TimeLocationFunc(func(r *http.Request) *time.Location {
+		return time.FixedZone("UTC+8", 8*60*60)
+	}).Model(&User{})
 
 	ftRead := NewFieldDefaults(LIST)
 
@@ -136,6 +139,8 @@ func TestFields(t *testing.T) {
 <td>hello</td>
 
 <td>true</td>
+
+<td>2019-08-29 11:09</td>
 `,
 		},
 
@@ -146,7 +151,7 @@ func TestFields(t *testing.T) {
 					Only("Time1", "Int1").ToComponent(mb, user, vd, ctx)
 			},
 			expect: `
-<td>2019-08-29 11:09:29 +0800 CST</td>
+<td>2019-08-29 11:09</td>
 
 <td>2</td>
 `,
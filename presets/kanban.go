@@ -0,0 +1,187 @@
+package presets
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+const kanbanBoardPortalName = "kanbanBoard"
+const kanbanCardFieldName = "kanbanCard"
+
+type KanbanCardComponentFunc func(obj interface{}, ctx *web.EventContext) h.HTMLComponent
+
+type kanbanColumn struct {
+	value string
+	label string
+}
+
+type KanbanBuilder struct {
+	lb                *ListingBuilder
+	fieldName         string
+	columns           []*kanbanColumn
+	cardComponentFunc KanbanCardComponentFunc
+	isDefault         bool
+}
+
+// Kanban adds a board layout to the listing that groups the records into columns by the values of the field,
+// dragging a card to another column updates the field with the setter, validator and saver of the editing form
+func (b *ListingBuilder) Kanban(fieldName string) (r *KanbanBuilder) {
+	if b.kanban == nil || b.kanban.fieldName != fieldName {
+		b.kanban = &KanbanBuilder{lb: b, fieldName: fieldName}
+	}
+	return b.kanban
+}
+
+// Column adds a column of the records whose field equals value, in the order of the board
+func (b *KanbanBuilder) Column(value string, label string) (r *KanbanBuilder) {
+	b.columns = append(b.columns, &kanbanColumn{value: value, label: label})
+	return b
+}
+
+// CardComponentFunc renders the content of the cards, defaults to the listing fields
+func (b *KanbanBuilder) CardComponentFunc(v KanbanCardComponentFunc) (r *KanbanBuilder) {
+	b.cardComponentFunc = v
+	return b
+}
+
+// Default shows the board instead of the table when the layout is not chosen in url
+func (b *KanbanBuilder) Default(v bool) (r *KanbanBuilder) {
+	b.isDefault = v
+	return b
+}
+
+func (b *KanbanBuilder) getColumn(value string) *kanbanColumn {
+	for _, c := range b.columns {
+		if c.value == value {
+			return c
+		}
+	}
+	return nil
+}
+
+// board searches the records of every column with the listing search, at most the per page of the listing in a column
+func (b *KanbanBuilder) board(msgr *Messages, alert h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	searchParams, _ := b.lb.searchParamsFromQuery(ctx)
+	searchParams.Page = 1
+
+	var cols []h.HTMLComponent
+	for _, c := range b.columns {
+		sp := *searchParams
+		sp.SQLConditions = append(append([]*SQLCondition{}, searchParams.SQLConditions...), &SQLCondition{
+			Query: fmt.Sprintf("%s = ?", sortKey(b.fieldName)),
+			Args:  []interface{}{c.value},
+		})
+
+		objs, totalCount, err := b.lb.searcher(b.lb.mb.newModelArray(), &sp, ctx)
+		if err != nil {
+			panic(err)
+		}
+
+		var cards []h.HTMLComponent
+		rv := reflect.ValueOf(objs)
+		for i := 0; i < rv.Len(); i++ {
+			cards = append(cards, b.card(rv.Index(i).Interface(), ctx))
+		}
+		if totalCount > rv.Len() {
+			cards = append(cards, h.Div(h.Text(msgr.KanbanMore(totalCount-rv.Len()))).
+				Class("caption grey--text text-center"))
+		}
+
+		cols = append(cols, VCol(
+			VCard(
+				VCardTitle(
+					h.Text(c.label),
+					VChip(h.Text(fmt.Sprint(totalCount))).Small(true).Class("ml-2"),
+				).Class("subtitle-1"),
+				VCardText(cards...).
+					Attr("style", "min-height: 120px;"),
+			).Flat(true).
+				Class("grey lighten-4").
+				Attr("@dragover.prevent", "").
+				Attr("@drop.prevent", web.Plaid().
+					EventFunc(actions.MoveKanbanCard, c.value).
+					FieldValue(kanbanCardFieldName, web.Var("vars.kanbanCard")).
+					Go()),
+		))
+	}
+
+	return h.Div(
+		alert,
+		VRow(cols...),
+	).Attr(web.InitContextVars, `{kanbanCard: ""}`)
+}
+
+func (b *KanbanBuilder) card(obj interface{}, ctx *web.EventContext) h.HTMLComponent {
+	id := b.lb.mb.objectID(obj)
+
+	var content h.HTMLComponent
+	if b.cardComponentFunc != nil {
+		content = b.cardComponentFunc(obj, ctx)
+	} else {
+		content = b.defaultCardContent(obj, ctx)
+	}
+
+	card := VCard(content).Class("mb-2")
+	if click := b.lb.rowClickEvent(id, ctx); len(click) > 0 {
+		card.Attr("@click", click)
+	}
+
+	if b.lb.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn(b.fieldName).WithReq(ctx.R).IsAllowed() == nil {
+		card.Attr("draggable", "true").
+			Attr("@dragstart", fmt.Sprintf("vars.kanbanCard = %s", h.JSONString(id)))
+	}
+	return card
+}
+
+// defaultCardContent shows the first listing field as the title, and the others below it
+func (b *KanbanBuilder) defaultCardContent(obj interface{}, ctx *web.EventContext) h.HTMLComponent {
+	var children []h.HTMLComponent
+	for _, f := range b.lb.visibleFields(ctx.R) {
+		if f.name == b.fieldName {
+			continue
+		}
+
+		class := "caption grey--text text--darken-1"
+		if len(children) == 0 {
+			class = "body-2 font-weight-medium"
+		}
+		children = append(children, h.Div(
+			tdToDiv(f.compFunc(obj, b.lb.mb.getComponentFuncField(f), ctx)),
+		).Class(class))
+	}
+	return VCardText(children...).Class("pa-3")
+}
+
+// moveKanbanCard sets the field to the value of the column that the card is dropped in,
+// and renders the board again with the errors if it fails
+func (b *KanbanBuilder) moveKanbanCard(ctx *web.EventContext) (r web.EventResponse, err error) {
+	msgr := MustGetMessages(ctx.R)
+	value := ctx.Event.Params[0]
+	id := ctx.R.FormValue(kanbanCardFieldName)
+	if b.getColumn(value) == nil || len(id) == 0 {
+		err = fmt.Errorf("can't move kanban card %s to %s", id, value)
+		return
+	}
+
+	_, err1 := b.lb.updateFields(b.lb.inlineEditingBuilder(b.fieldName), id, formContext(url.Values{b.fieldName: []string{value}}, ctx))
+
+	var alert h.HTMLComponent
+	if err1 != nil {
+		alert = VAlert(h.Text(fieldErrorMessage(err1, b.fieldName))).
+			Dense(true).
+			Type("error").
+			Class("mb-3")
+	}
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: kanbanBoardPortalName,
+		Body: b.board(msgr, alert, ctx),
+	})
+	return
+}
@@ -0,0 +1,97 @@
+package presets
+
+import (
+	"net/url"
+
+	"github.com/goplaid/web"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/goplaid/x/vuetifyx"
+	h "github.com/theplant/htmlgo"
+)
+
+const layoutParamName = "layout"
+const tableLayout = "table"
+const kanbanLayout = "kanban"
+const calendarLayout = "calendar"
+const treeLayout = "tree"
+
+// currentLayout is the layout chosen in url, or the default one of the listing
+func (b *ListingBuilder) currentLayout(ctx *web.EventContext) string {
+	switch ctx.R.URL.Query().Get(layoutParamName) {
+	case tableLayout:
+		return tableLayout
+	case kanbanLayout:
+		if b.kanban != nil {
+			return kanbanLayout
+		}
+	case calendarLayout:
+		if b.calendar != nil {
+			return calendarLayout
+		}
+	case treeLayout:
+		if b.tree != nil {
+			return treeLayout
+		}
+	}
+
+	if b.kanban != nil && b.kanban.isDefault {
+		return kanbanLayout
+	}
+	if b.calendar != nil && b.calendar.isDefault {
+		return calendarLayout
+	}
+	if b.tree != nil && b.tree.isDefault {
+		return treeLayout
+	}
+	return tableLayout
+}
+
+func (b *ListingBuilder) layoutToggle(msgr *Messages, ctx *web.EventContext) h.HTMLComponent {
+	if b.kanban == nil && b.calendar == nil && b.tree == nil {
+		return nil
+	}
+
+	current := b.currentLayout(ctx)
+	layoutBtn := func(layout string, icon string, title string) h.HTMLComponent {
+		btn := VBtn("").Icon(true).Children(
+			VIcon(icon),
+		).Attr("title", title).
+			Attr("@click", web.Plaid().
+				PushStateQuery(url.Values{
+					layoutParamName: []string{layout},
+					"page":          []string{},
+				}).
+				MergeQuery(true).
+				Go())
+		if layout == current {
+			btn.Color("primary")
+		}
+		return btn
+	}
+
+	toggle := h.Div(
+		layoutBtn(tableLayout, "view_list", msgr.TableLayout),
+	).Class("ml-2 d-flex")
+	if b.kanban != nil {
+		toggle.AppendChildren(layoutBtn(kanbanLayout, "view_week", msgr.KanbanLayout))
+	}
+	if b.calendar != nil {
+		toggle.AppendChildren(layoutBtn(calendarLayout, "event", msgr.CalendarLayout))
+	}
+	if b.tree != nil {
+		toggle.AppendChildren(layoutBtn(treeLayout, "account_tree", msgr.TreeLayout))
+	}
+	return toggle
+}
+
+// layoutBody shows the records in the layouts other than the table, under the tabs and toolbar of the listing
+func (b *ListingBuilder) layoutBody(msgr *Messages, fd vuetifyx.FilterData, records h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	return VContainer(
+		b.liveUpdates(msgr),
+		b.filterTabs(msgr, ctx),
+		VCard(
+			b.newAndFilterToolbar(msgr, ctx, fd),
+		).Class("mb-3"),
+		records,
+	).Fluid(true)
+}
@@ -13,19 +13,36 @@ import (
 	s "github.com/goplaid/x/stripeui"
 	. "github.com/goplaid/x/vuetify"
 	"github.com/goplaid/x/vuetifyx"
+	"github.com/iancoleman/strcase"
+	"github.com/sunfmin/reflectutils"
 	h "github.com/theplant/htmlgo"
 )
 
 type ListingBuilder struct {
-	mb             *ModelBuilder
-	bulkActions    []*ActionBuilder
-	filterDataFunc FilterDataFunc
-	filterTabsFunc FilterTabsFunc
-	pageFunc       web.PageFunc
-	searcher       SearchFunc
-	searchColumns  []string
-	perPage        int64
-	orderBy        string
+	mb               *ModelBuilder
+	bulkActions      []*ActionBuilder
+	filterDataFunc   FilterDataFunc
+	filterTabsFunc   FilterTabsFunc
+	pageFunc         web.PageFunc
+	searcher         SearchFunc
+	searchColumns    []string
+	fullTextSearch   FullTextSearch
+	highlightFields  []string
+	perPage          int64
+	orderBy          string
+	sortableFields   []string
+	exportFormats    []ExportFormat
+	cursorPagination bool
+	inlineEditFields []string
+	columnChooser    bool
+	choosableColumns []string
+	aggregates       []*listingAggregate
+	groupBy          string
+	aggregator       AggregateFunc
+	kanban           *KanbanBuilder
+	calendar         *CalendarBuilder
+	tree             *TreeBuilder
+	liveUpdatesMode  LiveUpdatesMode
 	FieldBuilders
 }
 
@@ -70,6 +87,13 @@ func (b *ListingBuilder) OrderBy(v string) (r *ListingBuilder) {
 	return b
 }
 
+// SortableFields makes the listing headers of these fields clickable to sort by them,
+// only fields in here are accepted from the order_by url param
+func (b *ListingBuilder) SortableFields(vs ...string) (r *ListingBuilder) {
+	b.sortableFields = vs
+	return b
+}
+
 func (b *ListingBuilder) GetPageFunc() web.PageFunc {
 	if b.pageFunc != nil {
 		return b.pageFunc
@@ -78,6 +102,7 @@ func (b *ListingBuilder) GetPageFunc() web.PageFunc {
 }
 
 const selectedParamName = "selected"
+const orderByParamName = "order_by"
 const bulkPanelOpenParamName = "bulkOpen"
 const bulkPanelPortalName = "bulkPanel"
 const deleteConfirmPortalName = "deleteConfirm"
@@ -93,45 +118,36 @@ func (b *ListingBuilder) defaultPageFunc(ctx *web.EventContext) (r web.PageRespo
 	title := msgr.ListingObjectTitle(i18n.T(ctx.R, ModelsI18nModuleKey, b.mb.label))
 	r.PageTitle = title
 
-	perPage := b.perPage
-	if perPage == 0 {
-		perPage = 50
-	}
+	searchParams, fd := b.searchParamsFromQuery(ctx)
 
-	orderBy := b.orderBy
-	if len(orderBy) == 0 {
-		orderBy = fmt.Sprintf("%s DESC", b.mb.primaryField)
+	if b.searcher == nil || b.mb.p.dataOperator == nil {
+		panic("presets.New().DataOperator(...) required")
 	}
 
-	//time.Sleep(1 * time.Second)
-	urlQuery := ctx.R.URL.Query()
-	searchParams := &SearchParams{
-		KeywordColumns: b.searchColumns,
-		Keyword:        urlQuery.Get("keyword"),
-		PerPage:        perPage,
-		OrderBy:        orderBy,
+	switch b.currentLayout(ctx) {
+	case kanbanLayout:
+		r.Body = b.layoutBody(msgr, fd, web.Portal(b.kanban.board(msgr, nil, ctx)).Name(kanbanBoardPortalName), ctx)
+		return
+	case calendarLayout:
+		r.Body = b.layoutBody(msgr, fd, web.Portal(b.calendar.calendar(msgr, nil, ctx)).Name(calendarPortalName), ctx)
+		return
+	case treeLayout:
+		r.Body = b.layoutBody(msgr, fd, web.Portal(b.tree.tree(msgr, nil, ctx)).Name(treePortalName), ctx)
+		return
 	}
 
-	searchParams.Page, _ = strconv.ParseInt(urlQuery.Get("page"), 10, 64)
-	if searchParams.Page == 0 {
-		searchParams.Page = 1
+	if b.isGrouped() {
+		searchParams.OrderBy = fmt.Sprintf("%s ASC, %s", sortKey(b.groupBy), searchParams.OrderBy)
 	}
 
-	var fd vuetifyx.FilterData
-	if b.filterDataFunc != nil {
-		fd = b.filterDataFunc(ctx)
-
-		cond, args := fd.SetByQueryString(ctx.R.URL.RawQuery)
-
-		searchParams.SQLConditions = append(searchParams.SQLConditions, &SQLCondition{
-			Query: cond,
-			Args:  args,
-		})
+	perPage := searchParams.PerPage
+	if b.cursorPagination {
+		searchParams.Cursor = b.cursorFromQuery(ctx)
+		searchParams.PerPage++
 	}
 
-	if b.searcher == nil || b.mb.p.dataOperator == nil {
-		panic("presets.New().DataOperator(...) required")
-	}
+	fields := b.visibleFields(ctx.R)
+	searchParams.Associations = b.mb.manyToManyFields(fields)
 
 	var objs interface{}
 	var totalCount int
@@ -140,92 +156,222 @@ func (b *ListingBuilder) defaultPageFunc(ctx *web.EventContext) (r web.PageRespo
 		panic(err)
 	}
 
+	var pagination h.HTMLComponent
+	if b.cursorPagination {
+		var hasPrev, hasNext bool
+		objs, hasPrev, hasNext = cursorPage(objs, searchParams.Cursor, perPage)
+		pagination = b.cursorPaginator(objs, hasPrev, hasNext, ctx)
+	} else {
+		pagesCount := int(int64(totalCount)/perPage + 1)
+		if int64(totalCount)%perPage == 0 {
+			pagesCount--
+		}
+
+		pagination = h.If(pagesCount > 1, h.Components(
+			VPagination().
+				Length(pagesCount).
+				Value(int(searchParams.Page)).
+				Attr("@input", web.Plaid().
+					Query("page", web.Var("[$event]")).
+					MergeQuery(true).
+					Go()),
+		))
+	}
+
+	err = b.preloadBelongsTo(objs, fields, ctx)
+	if err != nil {
+		panic(err)
+	}
+
 	haveCheckboxes := len(b.bulkActions) > 0
 
 	selected := getSelectedIds(ctx)
+	allMatching := b.isAllMatchingSelected(ctx)
+	var matchingQuery string
+	if allMatching {
+		matchingQuery = ctx.R.URL.RawQuery
+	}
 
 	var toolbar h.HTMLComponent
 	var bulkPanel h.HTMLComponent
 	bulkName := ctx.R.URL.Query().Get(bulkPanelOpenParamName)
 	bulk := getAction(b.bulkActions, bulkName)
+	if bulk != nil && allMatching && bulk.updateMatchingFunc == nil {
+		bulk = nil
+	}
 	if bulk == nil {
-		if haveCheckboxes && len(selected) > 0 {
-			toolbar = b.bulkActionsToolbar(msgr, ctx)
+		if haveCheckboxes && (len(selected) > 0 || allMatching) {
+			toolbar = b.bulkActionsToolbar(msgr, allMatching, ctx)
 		} else {
 			toolbar = b.newAndFilterToolbar(msgr, ctx, fd)
 		}
 	} else {
-		bulkPanel = web.Portal(b.bulkPanel(bulk, selected, ctx)).Name(bulkPanelPortalName)
-	}
-
-	pagesCount := int(int64(totalCount)/searchParams.PerPage + 1)
-	if int64(totalCount)%searchParams.PerPage == 0 {
-		pagesCount--
+		bulkPanel = web.Portal(b.bulkPanel(bulk, selected, matchingQuery, nil, ctx)).Name(bulkPanelPortalName)
 	}
 
 	dataTable := s.DataTable(objs).
 		CellWrapperFunc(func(cell h.MutableAttrHTMLComponent, id string) h.HTMLComponent {
 			tdbind := cell
-			if b.mb.hasDetailing {
-				tdbind.SetAttr("@click.self", web.Plaid().
-					PushStateURL(
-						b.mb.Info().
-							DetailingHref(id)).
-					Go())
-			} else {
-				if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(b.mb.model).On(id).WithReq(ctx.R).IsAllowed() == nil {
-
-					tdbind.SetAttr("@click.self", web.Plaid().
-						EventFunc(actions.DrawerEdit, id).
-						Go())
-				}
-
+			if click := b.rowClickEvent(id, ctx); len(click) > 0 {
+				tdbind.SetAttr("@click.self", click)
 			}
 			return tdbind
 		}).
 		RowMenuItemsFunc(EditDeleteRowMenuItemsFunc(b.mb.Info(), "")).
 		Selectable(haveCheckboxes).
-		SelectionParamName(selectedParamName)
+		SelectionParamName(selectedParamName).
+		OrderByParamName(orderByParamName)
 
-	for _, f := range b.fields {
+	for _, f := range fields {
+		cf := b.cellComponentFunc(f)
+		if b.isInlineEditable(f.name) {
+			cf = b.inlineEditCellComponentFunc(f)
+		}
 		dataTable.Column(f.name).
 			Title(i18n.PT(ctx.R, ModelsI18nModuleKey, b.mb.label, b.mb.getLabel(f.NameLabel))).
-			CellComponentFunc(b.cellComponentFunc(f))
+			Sortable(b.isSortable(f.name)).
+			SortKey(sortKey(f.name)).
+			CellComponentFunc(cf)
 	}
 
-	r.Body = VContainer(
+	if len(fields) > 0 {
+		err = b.aggregate(dataTable, fields[0].name, searchParams, msgr, ctx)
+		if err != nil {
+			panic(err)
+		}
+	}
 
+	r.Body = VContainer(
+		b.liveUpdates(msgr),
 		b.filterTabs(msgr, ctx),
 		bulkPanel,
 		VCard(
 			toolbar,
 			VDivider(),
+			b.selectAllMatchingBanner(msgr, objs, selected, totalCount, allMatching, ctx),
 			VCardText(
 				web.Portal().Name(deleteConfirmPortalName),
 				dataTable,
 			).Class("pa-0"),
 		),
 
-		h.If(pagesCount > 1, h.Components(
-			VPagination().
-				Length(pagesCount).
-				Value(int(searchParams.Page)).
-				Attr("@input", web.Plaid().
-					Query("page", web.Var("[$event]")).
-					MergeQuery(true).
-					Go()),
-		)),
+		pagination,
 	).Fluid(true)
 
 	return
 }
 
+// rowClickEvent opens the detailing page of the record, or the editing drawer if there is no detailing
+func (b *ListingBuilder) rowClickEvent(id string, ctx *web.EventContext) string {
+	if b.mb.hasDetailing {
+		return web.Plaid().
+			PushStateURL(
+				b.mb.Info().
+					DetailingHref(id)).
+			Go()
+	}
+
+	if b.mb.Info().Verifier().Do(PermUpdate).ObjectOn(b.mb.model).On(id).WithReq(ctx.R).IsAllowed() == nil {
+		return web.Plaid().
+			EventFunc(actions.DrawerEdit, id).
+			Go()
+	}
+	return ""
+}
+
+func (b *ListingBuilder) defaultOrderBy() string {
+	if len(b.orderBy) > 0 {
+		return b.orderBy
+	}
+	return fmt.Sprintf("%s DESC", b.mb.primaryField)
+}
+
+// searchParamsFromQuery builds the search params of the current keyword, filters, order and page in url
+func (b *ListingBuilder) searchParamsFromQuery(ctx *web.EventContext) (searchParams *SearchParams, fd vuetifyx.FilterData) {
+	perPage := b.perPage
+	if perPage == 0 {
+		perPage = 50
+	}
+
+	urlQuery := ctx.R.URL.Query()
+
+	orderBy := b.defaultOrderBy()
+	sorted := false
+	if f, desc := b.sortableFieldFromQuery(urlQuery.Get(orderByParamName)); len(f) > 0 {
+		sorted = true
+		orderBy = fmt.Sprintf("%s ASC", sortKey(f))
+		if desc {
+			orderBy = fmt.Sprintf("%s DESC", sortKey(f))
+		}
+	}
+	searchParams = &SearchParams{
+		KeywordColumns: b.searchColumns,
+		Keyword:        strings.TrimSpace(urlQuery.Get("keyword")),
+		FullTextSearch: b.fullTextSearch,
+		RankByKeyword:  b.fullTextSearch != nil && !sorted,
+		PerPage:        perPage,
+		OrderBy:        orderBy,
+	}
+
+	searchParams.Page, _ = strconv.ParseInt(urlQuery.Get("page"), 10, 64)
+	if searchParams.Page == 0 {
+		searchParams.Page = 1
+	}
+
+	if b.filterDataFunc != nil {
+		fd = b.filterDataFunc(ctx)
+
+		cond, args := fd.SetByQueryString(ctx.R.URL.RawQuery)
+
+		searchParams.SQLConditions = append(searchParams.SQLConditions, &SQLCondition{
+			Query: cond,
+			Args:  args,
+		})
+	}
+	return
+}
+
 func (b *ListingBuilder) cellComponentFunc(f *FieldBuilder) s.CellComponentFunc {
 	return func(obj interface{}, fieldName string, ctx *web.EventContext) h.HTMLComponent {
+		if keyword := ctx.R.URL.Query().Get("keyword"); len(strings.TrimSpace(keyword)) > 0 && b.isHighlighted(f.name) {
+			return h.Td(keywordSnippet(fmt.Sprint(reflectutils.MustGet(obj, f.name)), keyword))
+		}
 		return f.compFunc(obj, b.mb.getComponentFuncField(f), ctx)
 	}
 }
 
+func sortKey(fieldName string) string {
+	return strcase.ToSnake(fieldName)
+}
+
+func (b *ListingBuilder) isSortable(fieldName string) bool {
+	for _, f := range b.sortableFields {
+		if f == fieldName {
+			return true
+		}
+	}
+	return false
+}
+
+// sortableFieldFromQuery finds the sortable field of order by values like name_desc,
+// anything not in the sortable fields is ignored so that it can't be injected into sql
+func (b *ListingBuilder) sortableFieldFromQuery(v string) (fieldName string, desc bool) {
+	key := v
+	if strings.HasSuffix(key, "_desc") {
+		key = strings.TrimSuffix(key, "_desc")
+		desc = true
+	} else {
+		key = strings.TrimSuffix(key, "_asc")
+	}
+
+	for _, f := range b.sortableFields {
+		if sortKey(f) == key {
+			return f, desc
+		}
+	}
+	return "", false
+}
+
 func getSelectedIds(ctx *web.EventContext) (selected []string) {
 	selectedValue := ctx.R.URL.Query().Get(selectedParamName)
 	if len(selectedValue) > 0 {
@@ -234,11 +380,23 @@ func getSelectedIds(ctx *web.EventContext) (selected []string) {
 	return selected
 }
 
-func (b *ListingBuilder) bulkPanel(bulk *ActionBuilder, selectedIds []string, ctx *web.EventContext) (r h.HTMLComponent) {
+// bulkPanel renders the form of the bulk action, matchingQuery is the listing query
+// when all the matching records are selected, and selectedIds is ignored
+func (b *ListingBuilder) bulkPanel(bulk *ActionBuilder, selectedIds []string, matchingQuery string, err error, ctx *web.EventContext) (r h.HTMLComponent) {
 	msgr := MustGetMessages(ctx.R)
 
+	if len(matchingQuery) > 0 {
+		selectedIds = nil
+	}
+
+	var alert h.HTMLComponent
+	if err != nil {
+		alert = VAlert(h.Text(err.Error())).Dense(true).Type("error")
+	}
+
 	return VCard(
 		VCardText(
+			alert,
 			bulk.compFunc(selectedIds, ctx),
 		),
 		VCardActions(
@@ -255,7 +413,7 @@ func (b *ListingBuilder) bulkPanel(bulk *ActionBuilder, selectedIds []string, ct
 				Color("primary").
 				Depressed(true).
 				Dark(true).
-				OnClick(actions.DoBulkAction, bulk.name, strings.Join(selectedIds, ",")),
+				OnClick(actions.DoBulkAction, bulk.name, strings.Join(selectedIds, ","), matchingQuery),
 		),
 	).Class("mb-5")
 }
@@ -307,21 +465,35 @@ func (b *ListingBuilder) doBulkAction(ctx *web.EventContext) (r web.EventRespons
 	}
 
 	selectedIds := strings.Split(ctx.Event.Params[1], ",")
-	err1 := bulk.updateFunc(selectedIds, ctx)
+	var matchingQuery string
+	if len(ctx.Event.Params) > 2 {
+		matchingQuery = ctx.Event.Params[2]
+	}
+
+	var err1 error
+	if len(matchingQuery) > 0 {
+		err1 = b.doBulkActionOnMatching(bulk, matchingQuery, ctx)
+	} else {
+		err1 = bulk.updateFunc(selectedIds, ctx)
+	}
 	if err1 != nil || ctx.Flash != nil {
 		r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
 			Name: bulkPanelPortalName,
-			Body: b.bulkPanel(bulk, selectedIds, ctx),
+			Body: b.bulkPanel(bulk, selectedIds, matchingQuery, err1, ctx),
 		})
 		return
 	}
 
-	r.PushState = web.PushState(url.Values{bulkPanelOpenParamName: []string{}, selectedParamName: []string{}}).MergeQuery(true)
+	r.PushState = web.PushState(url.Values{
+		bulkPanelOpenParamName:     []string{},
+		selectedParamName:          []string{},
+		selectAllMatchingParamName: []string{},
+	}).MergeQuery(true)
 
 	return
 }
 
-func (b *ListingBuilder) bulkActionsToolbar(msgr *Messages, ctx *web.EventContext) h.HTMLComponent {
+func (b *ListingBuilder) bulkActionsToolbar(msgr *Messages, allMatching bool, ctx *web.EventContext) h.HTMLComponent {
 	var toolbar = VToolbar(
 		VSpacer(),
 	).Flat(true)
@@ -331,6 +503,10 @@ func (b *ListingBuilder) bulkActionsToolbar(msgr *Messages, ctx *web.EventContex
 			continue
 		}
 
+		if allMatching && ba.updateMatchingFunc == nil {
+			continue
+		}
+
 		toolbar.AppendChildren(
 			VBtn(b.mb.getLabel(ba.NameLabel)).
 				Color("primary").
@@ -347,12 +523,16 @@ func (b *ListingBuilder) bulkActionsToolbar(msgr *Messages, ctx *web.EventContex
 }
 
 func (b *ListingBuilder) filterTabs(msgr *Messages, ctx *web.EventContext) (r h.HTMLComponent) {
-	if b.filterTabsFunc == nil {
+	views := b.listingViews(ctx)
+	if b.filterTabsFunc == nil && len(views) == 0 {
 		return
 	}
 
 	tabs := VTabs().Class("mb-3").Grow(true).Value(2)
-	tabsData := b.filterTabsFunc(ctx)
+	var tabsData []*FilterTab
+	if b.filterTabsFunc != nil {
+		tabsData = b.filterTabsFunc(ctx)
+	}
 	value := -1
 	rawQuery := ctx.R.URL.RawQuery
 	for i, td := range tabsData {
@@ -364,6 +544,21 @@ func (b *ListingBuilder) filterTabs(msgr *Messages, ctx *web.EventContext) (r h.
 				Attr("@click", web.Plaid().PushStateQuery(td.Query).Go()),
 		)
 	}
+
+	currentQuery := viewQuery(rawQuery)
+	for i, v := range views {
+		if v.Query == currentQuery {
+			value = len(tabsData) + i
+		}
+		query, _ := url.ParseQuery(v.Query)
+		tabs.AppendChildren(
+			VTab(
+				h.Text(v.Name),
+				VIcon("close").Small(true).Class("ml-1").
+					Attr("@click.stop", web.Plaid().EventFunc(actions.DoDeleteView, v.Name).Go()),
+			).Attr("@click", web.Plaid().PushStateQuery(query).Go()),
+		)
+	}
 	return tabs.Value(value)
 }
 
@@ -398,10 +593,16 @@ func (b *ListingBuilder) newAndFilterToolbar(msgr *Messages, ctx *web.EventConte
 
 	var toolbar = VToolbar(
 		VSpacer(),
+		b.layoutToggle(msgr, ctx),
+		b.columnChooserMenu(msgr, ctx),
+		b.saveViewButton(msgr, ctx),
+		b.exportMenu(msgr, ctx),
+		b.mb.importing.importButton(msgr, ctx),
 		VBtn(msgr.New).
 			Color("primary").
 			Depressed(true).
 			Dark(true).
+			Class("ml-2").
 			OnClick(actions.DrawerNew, "").Disabled(disableNewBtn),
 	).Flat(true)
 	if fd != nil {
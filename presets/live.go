@@ -0,0 +1,199 @@
+package presets
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goplaid/web"
+	. "github.com/goplaid/x/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+//go:embed livejs
+var liveAssets embed.FS
+
+func liveJSComponentsPack() web.ComponentsPack {
+	v, err := liveAssets.ReadFile("livejs/live_updates.js")
+	if err != nil {
+		panic(err)
+	}
+	return web.ComponentsPack(v)
+}
+
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// ModelChange is a record of the model that is saved or deleted by presets, UserID is the user that changes it
+type ModelChange struct {
+	Model  string     `json:"model"`
+	ID     string     `json:"id"`
+	Type   ChangeType `json:"type"`
+	UserID string     `json:"-"`
+}
+
+// ChangeNotifier delivers the changes of the models to the open listings, implement it with something like
+// redis pub/sub or postgres listen/notify when the app runs in multiple processes
+type ChangeNotifier interface {
+	Notify(change *ModelChange)
+	// Subscribe receives the changes of the model until cancel is called
+	Subscribe(model string) (changes <-chan *ModelChange, cancel func())
+}
+
+// ChangeNotifier replaces the in-process notifier that only notifies the listings served by the same process
+func (b *Builder) ChangeNotifier(v ChangeNotifier) (r *Builder) {
+	b.changeNotifier = v
+	return b
+}
+
+type MemoryChangeNotifier struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan *ModelChange]struct{}
+}
+
+func NewMemoryChangeNotifier() (r *MemoryChangeNotifier) {
+	return &MemoryChangeNotifier{subscribers: map[string]map[chan *ModelChange]struct{}{}}
+}
+
+// Notify drops the change for the subscribers that are too slow to receive it, instead of blocking the saving
+func (n *MemoryChangeNotifier) Notify(change *ModelChange) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for ch := range n.subscribers[change.Model] {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+func (n *MemoryChangeNotifier) Subscribe(model string) (changes <-chan *ModelChange, cancel func()) {
+	ch := make(chan *ModelChange, 16)
+	n.mu.Lock()
+	if n.subscribers[model] == nil {
+		n.subscribers[model] = map[chan *ModelChange]struct{}{}
+	}
+	n.subscribers[model][ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.subscribers[model], ch)
+		n.mu.Unlock()
+	}
+}
+
+type LiveUpdatesMode string
+
+const (
+	// LiveUpdatesBanner shows the number of changes with a button to reload the listing
+	LiveUpdatesBanner LiveUpdatesMode = "banner"
+	// LiveUpdatesReload reloads the listing on every change
+	LiveUpdatesReload LiveUpdatesMode = "reload"
+)
+
+// LiveUpdates makes the open listings know the records created, updated or deleted by other users
+// with server-sent events, the changes of the user are not sent to the user
+func (b *ListingBuilder) LiveUpdates(v LiveUpdatesMode) (r *ListingBuilder) {
+	b.liveUpdatesMode = v
+	return b
+}
+
+const liveChangesPath = "/changes"
+
+// liveKeepAliveInterval keeps the connection from being closed by the proxies that time out idle connections
+const liveKeepAliveInterval = 30 * time.Second
+
+func (b *ModelBuilder) notifyChange(id string, t ChangeType, ctx *web.EventContext) {
+	if b.p.changeNotifier == nil || len(id) == 0 {
+		return
+	}
+	b.p.changeNotifier.Notify(&ModelChange{
+		Model:  b.uriName,
+		ID:     id,
+		Type:   t,
+		UserID: b.p.userID(ctx.R),
+	})
+}
+
+// changesHandler streams the changes of the model made by other users as server-sent events
+func (b *ListingBuilder) changesHandler(w http.ResponseWriter, r *http.Request) {
+	if b.mb.Info().Verifier().Do(PermList).WithReq(r).IsAllowed() != nil {
+		http.Error(w, "permission denied", http.StatusForbidden)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	changes, cancel := b.mb.p.changeNotifier.Subscribe(b.mb.uriName)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	userID := b.mb.p.userID(r)
+	keepAlive := time.NewTicker(liveKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case change := <-changes:
+			if len(userID) > 0 && change.UserID == userID {
+				continue
+			}
+			data, err := json.Marshal(change)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
+
+// liveUpdates reloads the listing or counts the changes for the banner when the changes come
+func (b *ListingBuilder) liveUpdates(msgr *Messages) h.HTMLComponent {
+	if len(b.liveUpdatesMode) == 0 || b.mb.p.changeNotifier == nil {
+		return nil
+	}
+
+	listener := h.Tag("presets-live-updates").Attr("url", b.mb.Info().ListingHref()+liveChangesPath)
+	if b.liveUpdatesMode == LiveUpdatesReload {
+		return listener.Attr("@change", web.Plaid().Reload().Go())
+	}
+
+	return h.Div(
+		listener.Attr("@change", "vars.liveChanges++"),
+		VAlert(
+			h.Div(
+				h.Text(msgr.LiveChanges("{{ vars.liveChanges }}")),
+				VSpacer(),
+				VBtn(msgr.LiveChangesReload).
+					Text(true).
+					Small(true).
+					Color("primary").
+					Attr("@click", fmt.Sprintf("vars.liveChanges = 0; %s", web.Plaid().Reload().Go())),
+			).Class("d-flex align-center"),
+		).Dense(true).
+			Type("info").
+			Attr("v-if", "vars.liveChanges > 0"),
+	).Attr(web.InitContextVars, `{liveChanges: 0}`)
+}
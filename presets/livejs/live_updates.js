@@ -0,0 +1,24 @@
+(function () {
+	window.__goplaidVueComponentRegisters = window.__goplaidVueComponentRegisters || [];
+	window.__goplaidVueComponentRegisters.push(function (Vue) {
+		// presets-live-updates listens to the server-sent events of the url, and emits change events with the changes
+		Vue.component('presets-live-updates', {
+			props: {
+				url: String,
+			},
+			mounted: function () {
+				var self = this;
+				this.source = new EventSource(this.url);
+				this.source.addEventListener('change', function (e) {
+					self.$emit('change', JSON.parse(e.data));
+				});
+			},
+			beforeDestroy: function () {
+				this.source.close();
+			},
+			render: function (h) {
+				return h('span');
+			},
+		});
+	});
+})();
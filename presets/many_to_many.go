@@ -0,0 +1,196 @@
+package presets
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/perm"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/goplaid/x/vuetifyx"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+	"go.uber.org/zap"
+)
+
+// ManyToMany is the field type of the slices of a model registered in the builder like []*Tag, for the many-to-many
+// fields like Product.Tags. it shows chips in listings, links in detail pages, and a multi-select with the remote search
+// of the model in editing forms, the data operator saves the associations like the rows of the join table.
+// it should be called before the models with the fields are registered, that the fields are in their default fields
+func (b *Builder) ManyToMany(v interface{}) (r *Builder) {
+	if reflect.TypeOf(v).Kind() != reflect.Slice {
+		panic(fmt.Sprintf("many-to-many field type %T must be a slice", v))
+	}
+	b.listFieldDefaults.FieldType(v).ComponentFunc(manyToManyComponentFunc(LIST))
+	b.detailFieldDefaults.FieldType(v).ComponentFunc(manyToManyComponentFunc(DETAIL))
+	b.writeFieldDefaults.FieldType(v).ComponentFunc(manyToManyComponentFunc(WRITE)).SetterFunc(manyToManySetter)
+	b.manyToManyTypes = append(b.manyToManyTypes, reflect.TypeOf(v))
+	return b
+}
+
+// manyToMany finds the model registered in the builder that the slice field of the model refers to
+func (b *Builder) manyToMany(model interface{}, fieldName string) *ModelBuilder {
+	rm, err := b.findManyToMany(model, fieldName)
+	if err != nil {
+		panic(err)
+	}
+	return rm
+}
+
+// findManyToMany is manyToMany for the field names from requests, that fails for the fields that are not of
+// the types registered with ManyToMany
+func (b *Builder) findManyToMany(model interface{}, fieldName string) (r *ModelBuilder, err error) {
+	t := reflectutils.GetType(model, fieldName)
+	if t == nil || t.Kind() != reflect.Slice {
+		err = fmt.Errorf("%s of %T must be a slice", fieldName, model)
+		return
+	}
+	if !b.isManyToMany(model, fieldName) {
+		err = fmt.Errorf("%s of %T is not a many-to-many field", fieldName, model)
+		return
+	}
+	et := t.Elem()
+	if et.Kind() != reflect.Ptr {
+		et = reflect.PtrTo(et)
+	}
+	for _, m := range b.models {
+		if m.modelType == et {
+			return m, nil
+		}
+	}
+	err = fmt.Errorf("the model of %s of %T must be registered", fieldName, model)
+	return
+}
+
+// isManyToMany tells if the field is of a type registered with ManyToMany
+func (b *Builder) isManyToMany(model interface{}, fieldName string) bool {
+	t := reflectutils.GetType(model, fieldName)
+	for _, mt := range b.manyToManyTypes {
+		if t == mt {
+			return true
+		}
+	}
+	return false
+}
+
+// manyToManyFields are the names of the many-to-many fields in fields, that are loaded with the records to show them
+func (b *ModelBuilder) manyToManyFields(fields []*FieldBuilder) (r []string) {
+	for _, f := range fields {
+		if b.p.isManyToMany(b.model, f.name) {
+			r = append(r, f.name)
+		}
+	}
+	return
+}
+
+func manyToManyComponentFunc(mode FieldMode) FieldComponentFunc {
+	return func(obj interface{}, field *FieldContext, ctx *web.EventContext) h.HTMLComponent {
+		rm := field.ModelInfo.p.manyToMany(obj, field.Name)
+		rows := reflect.ValueOf(reflectutils.MustGet(obj, field.Name))
+
+		var comps []h.HTMLComponent
+		var selected []*autocompleteOption
+		var values []interface{}
+		for i := 0; i < rows.Len(); i++ {
+			robj := rows.Index(i).Interface()
+			if rows.Index(i).Kind() != reflect.Ptr {
+				robj = rows.Index(i).Addr().Interface()
+			}
+			id := rm.objectID(robj)
+
+			switch mode {
+			case LIST:
+				comps = append(comps, VChip(h.Text(getPageTitle(robj, id))).Small(true).Class("mr-1"))
+			case DETAIL:
+				if i > 0 {
+					comps = append(comps, h.Text(", "))
+				}
+				comps = append(comps, relatedLink(rm, robj, id, ctx))
+			default:
+				option := rm.relatedOption(robj)
+				selected = append(selected, option)
+				values = append(values, option.Value)
+			}
+		}
+
+		switch mode {
+		case LIST:
+			return h.Td(comps...)
+		case DETAIL:
+			return h.Div(
+				h.Div(h.Text(field.Label)).Class("text-caption grey--text"),
+				h.Div(comps...),
+			).Class("mb-4")
+		}
+
+		return h.Div(
+			h.Input("").Type("hidden").Value("1").Attr(web.VFieldName(manyToManyFieldName(field.Name))...),
+			vuetifyx.VXAutocomplete().
+				FieldName(field.Name).
+				Label(field.Label).
+				ItemText("text").
+				ItemValue("value").
+				Multiple(true).
+				SelectedItems(selected).
+				ItemsEventFunc(actions.ManyToManyOptions, field.Name).
+				Value(values).
+				ErrorMessages(field.Errors...),
+		)
+	}
+}
+
+// manyToManyFieldName is the form field that tells the field is in the form, the field itself
+// is not in the form if nothing is chosen
+func manyToManyFieldName(fieldName string) string {
+	return "__ManyToMany_" + fieldName
+}
+
+// manyToManySetter sets the records of the ids chosen in the form to the field, it's not changed if it's not in the form,
+// the records are searched at once, and the user can only choose from the records that the user can list
+func manyToManySetter(obj interface{}, field *FieldContext, ctx *web.EventContext) (err error) {
+	if len(ctx.R.FormValue(manyToManyFieldName(field.Name))) == 0 {
+		return
+	}
+	rm := field.ModelInfo.p.manyToMany(obj, field.Name)
+	if rm.Info().Verifier().Do(PermList).WithReq(ctx.R).IsAllowed() != nil {
+		return perm.PermissionDenied
+	}
+	t := reflectutils.GetType(obj, field.Name)
+
+	var ids []interface{}
+	for _, id := range ctx.R.Form[field.Name] {
+		ids = append(ids, id)
+	}
+	records, err := rm.searchByIDs(ids, ctx)
+	if err != nil {
+		return
+	}
+
+	rows := reflect.MakeSlice(t, 0, len(ids))
+	for _, id := range ctx.R.Form[field.Name] {
+		robj, ok := records[id]
+		if !ok {
+			return errors.New(MustGetMessages(ctx.R).InvalidFieldValue)
+		}
+		rv := reflect.ValueOf(robj)
+		if t.Elem().Kind() != reflect.Ptr {
+			rv = rv.Elem()
+		}
+		rows = reflect.Append(rows, rv)
+	}
+	return reflectutils.Set(obj, field.Name, rows.Interface())
+}
+
+// manyToManyOptions searches the options of the multi-select with the searcher of the model the field refers to,
+// there are no options for the fields in the request that are not many-to-many fields
+func (b *ModelBuilder) manyToManyOptions(ctx *web.EventContext) (r web.EventResponse, err error) {
+	rm, err1 := b.p.findManyToMany(b.model, ctx.Event.Params[0])
+	if err1 != nil {
+		b.p.logger.Warn("many-to-many options", zap.Error(err1))
+		r.Data = []*autocompleteOption{}
+		return
+	}
+	return rm.relatedOptions(ctx)
+}
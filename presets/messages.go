@@ -1,6 +1,7 @@
 package presets
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -14,6 +15,71 @@ type Messages struct {
 	OK                             string
 	Cancel                         string
 	Create                         string
+	Export                         string
+	ExportAsTemplate               string
+	Import                         string
+	ImportingObjectsTitleTemplate  string
+	ImportCSVFile                  string
+	ImportCSVFileRequired          string
+	ImportInvalidCSVFile           string
+	ImportNext                     string
+	ImportColumnMapping            string
+	ImportSkipColumn               string
+	ImportRow                      string
+	ImportErrors                   string
+	ImportErrorReport              string
+	ImportPreviewSummaryTemplate   string
+	ImportDoneTemplate             string
+	ImportStoppedTemplate          string
+	ImportNotImported              string
+	ImportFileExpired              string
+	SaveView                       string
+	ViewName                       string
+	ViewNameRequired               string
+	AllOnPageSelectedTemplate      string
+	BulkActionNotForAllMatching    string
+	SelectAllMatchingTemplate      string
+	AllMatchingSelectedTemplate    string
+	ClearSelection                 string
+	Columns                        string
+	ResetColumns                   string
+	AggregateTotal                 string
+	AggregateSum                   string
+	AggregateAvg                   string
+	AggregateCount                 string
+	AggregateMin                   string
+	AggregateMax                   string
+	TableLayout                    string
+	KanbanLayout                   string
+	KanbanMoreTemplate             string
+	CalendarLayout                 string
+	CalendarToday                  string
+	CalendarMonth                  string
+	CalendarWeek                   string
+	CalendarDay                    string
+	TreeLayout                     string
+	TreeAddChild                   string
+	TreeMoveToRoot                 string
+	TreeCycleError                 string
+	GlobalSearchHint               string
+	GlobalSearchNoResultsTemplate  string
+	GlobalSearchViewAllTemplate    string
+	LiveChangesTemplate            string
+	LiveChangesReload              string
+	ValidateRequired               string
+	ValidateEmail                  string
+	ValidateMinTemplate            string
+	ValidateMaxTemplate            string
+	ValidateMinLengthTemplate      string
+	ValidateMaxLengthTemplate      string
+	ValidateOneOfTemplate          string
+	ValidateTime                   string
+	InvalidFieldValue              string
+	HasManyAddRow                  string
+	AttachmentRemove               string
+	LockConflict                   string
+	LockConflictSavedValueTemplate string
+	LockConflictRetry              string
 	DeleteConfirmationTextTemplate string
 	CreatingObjectTitleTemplate    string
 	EditingObjectTitleTemplate     string
@@ -47,6 +113,91 @@ func (msgr *Messages) DeleteConfirmationText(id string) string {
 		Replace(msgr.DeleteConfirmationTextTemplate)
 }
 
+func (msgr *Messages) ExportAs(format string) string {
+	return strings.NewReplacer("{format}", format).
+		Replace(msgr.ExportAsTemplate)
+}
+
+func (msgr *Messages) ImportingObjectsTitle(modelsName string) string {
+	return strings.NewReplacer("{modelsName}", modelsName).
+		Replace(msgr.ImportingObjectsTitleTemplate)
+}
+
+func (msgr *Messages) ImportPreviewSummary(valid int, invalid int) string {
+	return strings.NewReplacer("{valid}", fmt.Sprint(valid), "{invalid}", fmt.Sprint(invalid)).
+		Replace(msgr.ImportPreviewSummaryTemplate)
+}
+
+func (msgr *Messages) ImportDone(imported int, failed int) string {
+	return strings.NewReplacer("{imported}", fmt.Sprint(imported), "{failed}", fmt.Sprint(failed)).
+		Replace(msgr.ImportDoneTemplate)
+}
+
+func (msgr *Messages) ImportStopped(line int, imported int) string {
+	return strings.NewReplacer("{line}", fmt.Sprint(line), "{imported}", fmt.Sprint(imported)).
+		Replace(msgr.ImportStoppedTemplate)
+}
+
+func (msgr *Messages) AllOnPageSelected(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.AllOnPageSelectedTemplate)
+}
+
+func (msgr *Messages) SelectAllMatching(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.SelectAllMatchingTemplate)
+}
+
+func (msgr *Messages) AllMatchingSelected(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.AllMatchingSelectedTemplate)
+}
+
+func (msgr *Messages) KanbanMore(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.KanbanMoreTemplate)
+}
+
+func (msgr *Messages) GlobalSearchNoResults(keyword string) string {
+	return strings.NewReplacer("{keyword}", keyword).
+		Replace(msgr.GlobalSearchNoResultsTemplate)
+}
+
+func (msgr *Messages) GlobalSearchViewAll(count int) string {
+	return strings.NewReplacer("{count}", fmt.Sprint(count)).
+		Replace(msgr.GlobalSearchViewAllTemplate)
+}
+
+func (msgr *Messages) LiveChanges(count string) string {
+	return strings.NewReplacer("{count}", count).
+		Replace(msgr.LiveChangesTemplate)
+}
+
+// ValidateLimit is the message of the min and max rules, of the length for strings and slices
+func (msgr *Messages) ValidateLimit(rule string, limit string, isLength bool) string {
+	template := msgr.ValidateMaxTemplate
+	switch {
+	case rule == "min" && isLength:
+		template = msgr.ValidateMinLengthTemplate
+	case rule == "min":
+		template = msgr.ValidateMinTemplate
+	case isLength:
+		template = msgr.ValidateMaxLengthTemplate
+	}
+	return strings.NewReplacer("{limit}", limit).
+		Replace(template)
+}
+
+func (msgr *Messages) ValidateOneOf(values string) string {
+	return strings.NewReplacer("{values}", values).
+		Replace(msgr.ValidateOneOfTemplate)
+}
+
+func (msgr *Messages) LockConflictSavedValue(value string) string {
+	return strings.NewReplacer("{value}", value).
+		Replace(msgr.LockConflictSavedValueTemplate)
+}
+
 func (msgr *Messages) CreatingObjectTitle(modelName string) string {
 	return strings.NewReplacer("{modelName}", modelName).
 		Replace(msgr.CreatingObjectTitleTemplate)
@@ -80,6 +231,71 @@ var Messages_en_US = &Messages{
 	OK:                             "OK",
 	Cancel:                         "Cancel",
 	Create:                         "Create",
+	Export:                         "Export",
+	ExportAsTemplate:               "Export as {format}",
+	Import:                         "Import",
+	ImportingObjectsTitleTemplate:  "Import {modelsName}",
+	ImportCSVFile:                  "CSV file",
+	ImportCSVFileRequired:          "Please choose a CSV file",
+	ImportInvalidCSVFile:           "The file is not a valid CSV file",
+	ImportNext:                     "Next",
+	ImportColumnMapping:            "Map columns to fields",
+	ImportSkipColumn:               "(Skip this column)",
+	ImportRow:                      "Row",
+	ImportErrors:                   "Errors",
+	ImportErrorReport:              "Download error report",
+	ImportPreviewSummaryTemplate:   "{valid} rows are valid, {invalid} rows have errors",
+	ImportDoneTemplate:             "{imported} rows imported, {failed} rows failed",
+	ImportStoppedTemplate:          "Import stopped at row {line} that failed to save, {imported} rows imported before it",
+	ImportNotImported:              "Not imported",
+	ImportFileExpired:              "The uploaded file is expired, please upload it again",
+	SaveView:                       "Save view",
+	ViewName:                       "View name",
+	ViewNameRequired:               "Please input a name for the view",
+	AllOnPageSelectedTemplate:      "All {count} records on this page are selected.",
+	BulkActionNotForAllMatching:    "This action can't be done on all the matching records",
+	SelectAllMatchingTemplate:      "Select all {count} matching records",
+	AllMatchingSelectedTemplate:    "All {count} matching records are selected.",
+	ClearSelection:                 "Clear selection",
+	Columns:                        "Columns",
+	ResetColumns:                   "Reset columns",
+	AggregateTotal:                 "Total",
+	AggregateSum:                   "Sum",
+	AggregateAvg:                   "Avg",
+	AggregateCount:                 "Count",
+	AggregateMin:                   "Min",
+	AggregateMax:                   "Max",
+	TableLayout:                    "Table",
+	KanbanLayout:                   "Board",
+	KanbanMoreTemplate:             "{count} more",
+	CalendarLayout:                 "Calendar",
+	CalendarToday:                  "Today",
+	CalendarMonth:                  "Month",
+	CalendarWeek:                   "Week",
+	CalendarDay:                    "Day",
+	TreeLayout:                     "Tree",
+	TreeAddChild:                   "Add child",
+	TreeMoveToRoot:                 "Drop here to move to the top level",
+	TreeCycleError:                 "Can't move it under itself or its descendants",
+	GlobalSearchHint:               "Type a keyword in the search box to search all the records",
+	GlobalSearchNoResultsTemplate:  "No results for {keyword}",
+	GlobalSearchViewAllTemplate:    "View all {count}",
+	LiveChangesTemplate:            "{count} new changes",
+	LiveChangesReload:              "Reload",
+	ValidateRequired:               "Required",
+	ValidateEmail:                  "Invalid email address",
+	ValidateMinTemplate:            "Must be at least {limit}",
+	ValidateMaxTemplate:            "Must be at most {limit}",
+	ValidateMinLengthTemplate:      "Must be at least {limit} characters",
+	ValidateMaxLengthTemplate:      "Must be at most {limit} characters",
+	ValidateOneOfTemplate:          "Must be one of {values}",
+	ValidateTime:                   "Invalid date or time",
+	InvalidFieldValue:              "Invalid value",
+	HasManyAddRow:                  "Add",
+	AttachmentRemove:               "Remove",
+	LockConflict:                   "It was updated by someone else after you opened it, the fields different from the saved ones are marked, update again to overwrite them",
+	LockConflictSavedValueTemplate: "Saved value: {value}",
+	LockConflictRetry:              "It was updated by someone else, reload and try again",
 	Filters:                        "Filters",
 	Filter:                         "Filter",
 	FiltersClear:                   "Clear",
@@ -118,6 +334,71 @@ var Messages_zh_CN = &Messages{
 	OK:                             "确定",
 	Cancel:                         "取消",
 	Create:                         "创建",
+	Export:                         "导出",
+	ExportAsTemplate:               "导出为{format}",
+	Import:                         "导入",
+	ImportingObjectsTitleTemplate:  "导入{modelsName}",
+	ImportCSVFile:                  "CSV文件",
+	ImportCSVFileRequired:          "请选择CSV文件",
+	ImportInvalidCSVFile:           "文件不是有效的CSV文件",
+	ImportNext:                     "下一步",
+	ImportColumnMapping:            "设置列对应的字段",
+	ImportSkipColumn:               "(跳过此列)",
+	ImportRow:                      "行",
+	ImportErrors:                   "错误",
+	ImportErrorReport:              "下载错误报告",
+	ImportPreviewSummaryTemplate:   "{valid}行有效，{invalid}行有错误",
+	ImportDoneTemplate:             "已导入{imported}行，{failed}行失败",
+	ImportStoppedTemplate:          "导入在第{line}行保存失败时停止，之前已导入{imported}行",
+	ImportNotImported:              "未导入",
+	ImportFileExpired:              "上传的文件已过期，请重新上传",
+	SaveView:                       "保存视图",
+	ViewName:                       "视图名称",
+	ViewNameRequired:               "请输入视图名称",
+	AllOnPageSelectedTemplate:      "已选择本页全部{count}条记录。",
+	BulkActionNotForAllMatching:    "此操作不能用于所有匹配的记录",
+	SelectAllMatchingTemplate:      "选择全部{count}条符合条件的记录",
+	AllMatchingSelectedTemplate:    "已选择全部{count}条符合条件的记录。",
+	ClearSelection:                 "清除选择",
+	Columns:                        "列",
+	ResetColumns:                   "重置列",
+	AggregateTotal:                 "总计",
+	AggregateSum:                   "合计",
+	AggregateAvg:                   "平均",
+	AggregateCount:                 "计数",
+	AggregateMin:                   "最小",
+	AggregateMax:                   "最大",
+	TableLayout:                    "表格",
+	KanbanLayout:                   "看板",
+	KanbanMoreTemplate:             "还有 {count} 条",
+	CalendarLayout:                 "日历",
+	CalendarToday:                  "今天",
+	CalendarMonth:                  "月",
+	CalendarWeek:                   "周",
+	CalendarDay:                    "日",
+	TreeLayout:                     "树",
+	TreeAddChild:                   "添加子项",
+	TreeMoveToRoot:                 "拖到这里移到顶层",
+	TreeCycleError:                 "不能移到它自己或它的子项下",
+	GlobalSearchHint:               "在搜索框输入关键词搜索所有记录",
+	GlobalSearchNoResultsTemplate:  "没有找到 {keyword} 的结果",
+	GlobalSearchViewAllTemplate:    "查看全部 {count} 条",
+	LiveChangesTemplate:            "有 {count} 条新的修改",
+	LiveChangesReload:              "刷新",
+	ValidateRequired:               "必填",
+	ValidateEmail:                  "邮箱格式不正确",
+	ValidateMinTemplate:            "不能小于 {limit}",
+	ValidateMaxTemplate:            "不能大于 {limit}",
+	ValidateMinLengthTemplate:      "至少 {limit} 个字符",
+	ValidateMaxLengthTemplate:      "最多 {limit} 个字符",
+	ValidateOneOfTemplate:          "必须是 {values} 之一",
+	ValidateTime:                   "日期或时间格式不正确",
+	InvalidFieldValue:              "无效的值",
+	HasManyAddRow:                  "添加",
+	AttachmentRemove:               "删除",
+	LockConflict:                   "在你打开之后已被他人更新，与已保存的值不同的字段已标出，再次更新将覆盖它们",
+	LockConflictSavedValueTemplate: "已保存的值：{value}",
+	LockConflictRetry:              "已被他人更新，请刷新后重试",
 	Filters:                        "筛选",
 	Filter:                         "筛选",
 	FiltersClear:                   "清除",
@@ -29,6 +29,7 @@ type ModelBuilder struct {
 	detailing     *DetailingBuilder
 	editing       *EditingBuilder
 	creating      *EditingBuilder
+	importing     *ImportingBuilder
 	writeFields   *FieldBuilders
 	hasDetailing  bool
 }
@@ -43,6 +44,8 @@ func NewModelBuilder(p *Builder, model interface{}) (r *ModelBuilder) {
 	modelName := modelstr[strings.LastIndex(modelstr, ".")+1:]
 	r.label = strcase.ToCamel(inflection.Plural(modelName))
 	r.uriName = inflection.Plural(strcase.ToKebab(modelName))
+	// the validate rules are parsed when the model is registered, that the invalid ones are logged on start
+	structValidateRules(r.modelType)
 
 	r.newListing()
 	r.newDetailing()
@@ -60,12 +63,50 @@ func (b *ModelBuilder) ensureEventFuncs(hub web.EventFuncHub) {
 	hub.RegisterEventFunc(actions.DoBulkAction, b.listing.doBulkAction)
 	hub.RegisterEventFunc(actions.DrawerAction, b.detailing.formDrawerAction)
 	hub.RegisterEventFunc(actions.DoAction, b.detailing.doAction)
+	hub.RegisterEventFunc(actions.InlineEdit, b.listing.inlineEdit)
+	hub.RegisterEventFunc(actions.CancelInlineEdit, b.listing.cancelInlineEdit)
+	hub.RegisterEventFunc(actions.DoInlineEdit, b.listing.doInlineEdit)
+	hub.RegisterEventFunc(actions.UpdateListingColumns, b.listing.updateListingColumns)
+	hub.RegisterEventFunc(actions.SaveViewDialog, b.listing.saveViewDialog)
+	hub.RegisterEventFunc(actions.DoSaveView, b.listing.doSaveView)
+	hub.RegisterEventFunc(actions.DoDeleteView, b.listing.doDeleteView)
+	hub.RegisterEventFunc(actions.BelongsToOptions, b.belongsToOptions)
+	hub.RegisterEventFunc(actions.ManyToManyOptions, b.manyToManyOptions)
+	hub.RegisterEventFunc(actions.AddHasManyRow, b.editing.addHasManyRow)
+	hub.RegisterEventFunc(actions.RemoveHasManyRow, b.editing.removeHasManyRow)
+	hub.RegisterEventFunc(actions.MoveHasManyRow, b.editing.moveHasManyRow)
+	if b.importing != nil {
+		hub.RegisterEventFunc(actions.DrawerImport, b.importing.drawerImport)
+		hub.RegisterEventFunc(actions.ImportUpload, b.importing.importUpload)
+		hub.RegisterEventFunc(actions.ImportPreview, b.importing.importPreview)
+		hub.RegisterEventFunc(actions.DoImport, b.importing.doImport)
+	}
+	if b.listing.kanban != nil {
+		hub.RegisterEventFunc(actions.MoveKanbanCard, b.listing.kanban.moveKanbanCard)
+	}
+	if b.listing.calendar != nil {
+		hub.RegisterEventFunc(actions.RescheduleCalendarEvent, b.listing.calendar.rescheduleCalendarEvent)
+	}
+	if b.listing.tree != nil {
+		hub.RegisterEventFunc(actions.LoadTreeChildren, b.listing.tree.loadTreeChildren)
+		hub.RegisterEventFunc(actions.MoveTreeNode, b.listing.tree.moveTreeNode)
+	}
 }
 
 func (b *ModelBuilder) newModel() (r interface{}) {
 	return reflect.New(b.modelType.Elem()).Interface()
 }
 
+func (b *ModelBuilder) hasFieldOfType(ft reflect.Type) bool {
+	t := b.modelType.Elem()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == ft {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *ModelBuilder) newModelArray() (r interface{}) {
 	return reflect.New(reflect.SliceOf(b.modelType)).Interface()
 }
@@ -74,6 +115,9 @@ func (b *ModelBuilder) newListing() (r *ListingBuilder) {
 	b.listing = &ListingBuilder{mb: b, FieldBuilders: *b.p.listFieldDefaults.InspectFields(b.model)}
 	if b.p.dataOperator != nil {
 		b.listing.Searcher(b.p.dataOperator.Search)
+		if ag, ok := b.p.dataOperator.(Aggregator); ok {
+			b.listing.Aggregator(ag.Aggregate)
+		}
 	}
 	return
 }
@@ -82,8 +126,7 @@ func (b *ModelBuilder) newEditing() (r *EditingBuilder) {
 	b.writeFields, b.listing.searchColumns = b.p.writeFieldDefaults.inspectFieldsAndCollectName(b.model, reflect.TypeOf(""))
 	b.editing = &EditingBuilder{mb: b, FieldBuilders: *b.writeFields}
 	if b.p.dataOperator != nil {
-		b.editing.FetchFunc(b.p.dataOperator.Fetch)
-		b.editing.SaveFunc(b.p.dataOperator.Save)
+		b.editing.FetchFunc(b.editing.fetchWithAssociations)
 		b.editing.DeleteFunc(b.p.dataOperator.Delete)
 	}
 	return
@@ -92,7 +135,7 @@ func (b *ModelBuilder) newEditing() (r *EditingBuilder) {
 func (b *ModelBuilder) newDetailing() (r *DetailingBuilder) {
 	b.detailing = &DetailingBuilder{mb: b, FieldBuilders: *b.p.detailFieldDefaults.InspectFields(b.model)}
 	if b.p.dataOperator != nil {
-		b.detailing.Fetcher(b.p.dataOperator.Fetch)
+		b.detailing.Fetcher(b.detailing.fetchWithAssociations)
 	}
 	return
 }
@@ -112,6 +155,10 @@ func (b *ModelInfo) EditingHref(id string) string {
 	return fmt.Sprintf("%s/%s/%s/edit", b.p.prefix, b.uriName, id)
 }
 
+func (b *ModelInfo) NewingHref() string {
+	return fmt.Sprintf("%s/%s/new", b.p.prefix, b.uriName)
+}
+
 func (b *ModelInfo) DetailingHref(id string) string {
 	return fmt.Sprintf("%s/%s/%s", b.p.prefix, b.uriName, id)
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 
 	"github.com/goplaid/web"
@@ -21,28 +22,38 @@ import (
 )
 
 type Builder struct {
-	prefix              string
-	models              []*ModelBuilder
-	mux                 *goji.Mux
-	builder             *web.Builder
-	i18nBuilder         *i18n.Builder
-	logger              *zap.Logger
-	permissionBuilder   *perm.Builder
-	verifier            *perm.Verifier
-	dataOperator        DataOperator
-	messagesFunc        MessagesFunc
-	homePageFunc        web.PageFunc
-	brandFunc           ComponentFunc
-	profileFunc         ComponentFunc
-	brandTitle          string
-	vuetifyOptions      string
-	progressBarColor    string
-	rightDrawerWidth    int
-	writeFieldDefaults  *FieldDefaults
-	listFieldDefaults   *FieldDefaults
-	detailFieldDefaults *FieldDefaults
-	extraAssets         []*extraAsset
-	assetFunc           AssetFunc
+	prefix               string
+	models               []*ModelBuilder
+	mux                  *goji.Mux
+	builder              *web.Builder
+	i18nBuilder          *i18n.Builder
+	logger               *zap.Logger
+	permissionBuilder    *perm.Builder
+	verifier             *perm.Verifier
+	dataOperator         DataOperator
+	messagesFunc         MessagesFunc
+	homePageFunc         web.PageFunc
+	brandFunc            ComponentFunc
+	profileFunc          ComponentFunc
+	brandTitle           string
+	vuetifyOptions       string
+	progressBarColor     string
+	rightDrawerWidth     int
+	writeFieldDefaults   *FieldDefaults
+	listFieldDefaults    *FieldDefaults
+	detailFieldDefaults  *FieldDefaults
+	extraAssets          []*extraAsset
+	assetFunc            AssetFunc
+	listingViewStore     ListingViewStore
+	userIDFunc           UserIDFunc
+	listingColumnsStore  ListingColumnsStore
+	importFileStore      ImportFileStore
+	globalSearchPerModel int64
+	changeNotifier       ChangeNotifier
+	attachmentStorage    AttachmentStorage
+	timeLocationFunc     TimeLocationFunc
+	richTextSanitizer    HTMLSanitizer
+	manyToManyTypes      []reflect.Type
 	MenuGroups
 }
 
@@ -75,6 +86,8 @@ func New() *Builder {
 		brandTitle:          "Admin",
 		rightDrawerWidth:    600,
 		verifier:            perm.NewVerifier(PermModule, nil),
+		changeNotifier:      NewMemoryChangeNotifier(),
+		importFileStore:     NewMemoryImportFileStore(),
 	}
 }
 
@@ -408,6 +421,12 @@ func (b *Builder) defaultLayout(in web.PageFunc) (out web.PageFunc) {
 			profile = b.profileFunc(ctx)
 		}
 
+		// the search box filters the listing of the current page, and searches all the models on other pages
+		search := web.Plaid()
+		if !b.isListingPath(ctx.R.URL.Path) {
+			search.URL(b.globalSearchHref())
+		}
+
 		msgr := i18n.MustGetModuleMessages(ctx.R, CoreI18nModuleKey, Messages_en_US).(*Messages)
 
 		pr.PageTitle = fmt.Sprintf("%s - %s", innerPr.PageTitle, i18n.T(ctx.R, ModelsI18nModuleKey, b.brandTitle))
@@ -437,7 +456,7 @@ func (b *Builder) defaultLayout(in web.PageFunc) (out web.PageFunc) {
 						Clearable(true).
 						HideDetails(true).
 						Value(ctx.R.URL.Query().Get("keyword")).
-						Attr("@keyup.enter", web.Plaid().
+						Attr("@keyup.enter", search.
 							Query("keyword", web.Var("[$event.target.value]")).
 							Go()),
 					// ).Method("GET"),
@@ -491,6 +510,7 @@ func (b *Builder) initMux() {
 			Vuetify(b.vuetifyOptions),
 			JSComponentsPack(),
 			vuetifyx.JSComponentsPack(),
+			liveJSComponentsPack(),
 			web.JSComponentsPack(),
 		),
 	)
@@ -512,6 +532,7 @@ func (b *Builder) initMux() {
 	)
 	log.Println("mounted url", mainCSSPath)
 
+	b.registerRichTextAssets()
 	for _, ea := range b.extraAssets {
 		fullPath := b.extraFullPath(ea)
 		mux.Handle(pat.Get(fullPath), ub.PacksHandler(
@@ -526,10 +547,55 @@ func (b *Builder) initMux() {
 		b.wrap(nil, b.defaultLayout(b.getHomePageFunc())),
 	)
 
+	mux.Handle(
+		pat.New(b.globalSearchHref()),
+		b.wrap(nil, b.defaultLayout(b.globalSearchPageFunc)),
+	)
+	log.Println("mounted url", b.globalSearchHref())
+
 	for _, m := range b.models {
 		pluralUri := inflection.Plural(m.uriName)
 		info := m.Info()
 		routePath := info.ListingHref()
+		if len(m.listing.exportFormats) > 0 {
+			exportPath := routePath + "/export"
+			mux.Handle(
+				pat.Get(exportPath),
+				b.I18n().EnsureLanguage(http.HandlerFunc(m.listing.exportHandler)),
+			)
+			log.Println("mounted url", exportPath)
+		}
+		if len(m.listing.liveUpdatesMode) > 0 && b.changeNotifier != nil {
+			changesPath := routePath + liveChangesPath
+			mux.Handle(
+				pat.Get(changesPath),
+				http.HandlerFunc(m.listing.changesHandler),
+			)
+			log.Println("mounted url", changesPath)
+		}
+		if b.attachmentStorage != nil && m.hasFieldOfType(attachmentType) {
+			attachmentsPath := routePath + attachmentsPath
+			mux.Handle(
+				pat.Get(attachmentsPath),
+				b.I18n().EnsureLanguage(http.HandlerFunc(m.attachmentHandler)),
+			)
+			log.Println("mounted url", attachmentsPath)
+		}
+		// the pages of the new and edit are mounted before the detailing, that "new" isn't taken as the id
+		if m.editing.fullPage {
+			newPath := info.NewingHref()
+			mux.Handle(
+				pat.New(newPath),
+				b.wrap(m, b.defaultLayout(m.editing.newPageFunc)),
+			)
+			log.Println("mounted url", newPath)
+			editPath := info.EditingHref(":id")
+			mux.Handle(
+				pat.New(editPath),
+				b.wrap(m, b.defaultLayout(m.editing.editPageFunc)),
+			)
+			log.Println("mounted url", editPath)
+		}
 		mux.Handle(
 			pat.New(routePath),
 			b.wrap(m, b.defaultLayout(m.listing.GetPageFunc())),
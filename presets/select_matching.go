@@ -0,0 +1,106 @@
+package presets
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+
+	"github.com/goplaid/web"
+	. "github.com/goplaid/x/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+const selectAllMatchingParamName = "selectAllMatching"
+
+func (b *ListingBuilder) hasMatchingBulkActions() bool {
+	for _, ba := range b.bulkActions {
+		if ba.updateMatchingFunc != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllMatchingSelected is only supported with the total count of numbered pagination
+func (b *ListingBuilder) isAllMatchingSelected(ctx *web.EventContext) bool {
+	return !b.cursorPagination &&
+		b.hasMatchingBulkActions() &&
+		ctx.R.URL.Query().Get(selectAllMatchingParamName) == "1"
+}
+
+// selectAllMatchingBanner offers to select all the matching records when all on the page are selected
+func (b *ListingBuilder) selectAllMatchingBanner(msgr *Messages, objs interface{}, selected []string, totalCount int, allMatching bool, ctx *web.EventContext) h.HTMLComponent {
+	if allMatching {
+		return VAlert(
+			h.Text(msgr.AllMatchingSelected(totalCount)),
+			VBtn(msgr.ClearSelection).
+				Text(true).
+				Small(true).
+				Color("primary").
+				Attr("@click", web.Plaid().
+					PushStateQuery(url.Values{
+						selectedParamName:          []string{},
+						selectAllMatchingParamName: []string{},
+					}).
+					MergeQuery(true).
+					Go()),
+		).Dense(true).Text(true).Type("info").Class("ma-0 text-center")
+	}
+
+	if b.cursorPagination || !b.hasMatchingBulkActions() {
+		return nil
+	}
+
+	rv := reflect.ValueOf(objs)
+	if rv.Len() == 0 || totalCount <= rv.Len() {
+		return nil
+	}
+
+	selectedMap := map[string]bool{}
+	for _, id := range selected {
+		selectedMap[id] = true
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if !selectedMap[b.mb.objectID(rv.Index(i).Interface())] {
+			return nil
+		}
+	}
+
+	return VAlert(
+		h.Text(msgr.AllOnPageSelected(rv.Len())),
+		VBtn(msgr.SelectAllMatching(totalCount)).
+			Text(true).
+			Small(true).
+			Color("primary").
+			Attr("@click", web.Plaid().
+				PushStateQuery(url.Values{selectAllMatchingParamName: []string{"1"}}).
+				MergeQuery(true).
+				Go()),
+	).Dense(true).Text(true).Type("info").Class("ma-0 text-center")
+}
+
+// doBulkActionOnMatching runs the bulk action with the search params of the listing query,
+// without pagination so that all the matching records are updated
+func (b *ListingBuilder) doBulkActionOnMatching(bulk *ActionBuilder, matchingQuery string, ctx *web.EventContext) (err error) {
+	// the action is not shown for all the matching records, but the event could be sent anyway
+	if bulk.updateMatchingFunc == nil || b.cursorPagination {
+		return errors.New(MustGetMessages(ctx.R).BulkActionNotForAllMatching)
+	}
+
+	r := ctx.R.Clone(ctx.R.Context())
+	u := *ctx.R.URL
+	u.RawQuery = matchingQuery
+	r.URL = &u
+
+	searchParams, _ := b.searchParamsFromQuery(&web.EventContext{
+		R:        r,
+		W:        ctx.W,
+		Hub:      ctx.Hub,
+		Injector: ctx.Injector,
+		Event:    ctx.Event,
+	})
+	searchParams.PerPage = 0
+	searchParams.Page = 0
+
+	return bulk.updateMatchingFunc(searchParams, ctx)
+}
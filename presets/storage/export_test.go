@@ -0,0 +1,4 @@
+package storage
+
+// Sign is sign for the tests of the signatures
+var Sign = (*S3Builder).sign
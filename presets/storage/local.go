@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+func Local(dir string) (r *LocalBuilder) {
+	r = &LocalBuilder{dir: dir}
+	return
+}
+
+// LocalBuilder stores the attachments in the directory of the local file system, the keys are the paths in it
+type LocalBuilder struct {
+	dir string
+}
+
+// filePath cleans the key that it can't be the path out of the directory
+func (s *LocalBuilder) filePath(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (s *LocalBuilder) Put(key string, body io.Reader, size int64, contentType string) (err error) {
+	p := s.filePath(key)
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return
+	}
+	f, err := os.Create(p)
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(f, body)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return
+}
+
+func (s *LocalBuilder) Get(key string) (body io.ReadCloser, err error) {
+	return os.Open(s.filePath(key))
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+func S3(bucket string, region string) (r *S3Builder) {
+	r = &S3Builder{
+		bucket:   bucket,
+		region:   region,
+		endpoint: fmt.Sprintf("https://s3.%s.amazonaws.com", region),
+		client:   http.DefaultClient,
+	}
+	return
+}
+
+// S3Builder stores the attachments in the bucket of s3 or the s3 compatible services like minio,
+// the objects are requested in the path style, that the bucket is in the path of the endpoint
+type S3Builder struct {
+	bucket          string
+	region          string
+	endpoint        string
+	accessKeyID     string
+	secretAccessKey string
+	client          *http.Client
+}
+
+// Endpoint is the url of the s3 compatible services, like http://localhost:9000 of minio
+func (s *S3Builder) Endpoint(v string) (r *S3Builder) {
+	s.endpoint = strings.TrimRight(v, "/")
+	return s
+}
+
+func (s *S3Builder) Credentials(accessKeyID string, secretAccessKey string) (r *S3Builder) {
+	s.accessKeyID = accessKeyID
+	s.secretAccessKey = secretAccessKey
+	return s
+}
+
+func (s *S3Builder) HTTPClient(v *http.Client) (r *S3Builder) {
+	s.client = v
+	return s
+}
+
+func (s *S3Builder) Put(key string, body io.Reader, size int64, contentType string) (err error) {
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return
+	}
+	// s3 requires the length of the object, it can't be uploaded in chunks
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := s.do(req)
+	if err != nil {
+		return
+	}
+	return res.Body.Close()
+}
+
+func (s *S3Builder) Get(key string) (body io.ReadCloser, err error) {
+	req, err := http.NewRequest(http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return
+	}
+	return res.Body, nil
+}
+
+// objectURL encodes the key like the canonical uri of the signature, that the characters like ? and % are
+// in the key instead of being the query or the escapes
+func (s *S3Builder) objectURL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, uriEncode(strings.TrimLeft(key, "/"), false))
+}
+
+func (s *S3Builder) do(req *http.Request) (res *http.Response, err error) {
+	s.sign(req, time.Now())
+	res, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	if res.StatusCode/100 != 2 {
+		b, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		err = fmt.Errorf("s3 %s %s: %s %s", req.Method, req.URL.Path, res.Status, string(b))
+		return
+	}
+	return
+}
+
+// unsignedPayload skips the hash of the objects, that they are uploaded without being read twice
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// sign adds the authorization header of aws signature version 4 to the request, with the headers in it
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3Builder) sign(req *http.Request, t time.Time) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	if len(req.Header.Get("X-Amz-Content-Sha256")) == 0 {
+		req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, vs := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(vs, ","))
+	}
+	var names []string
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	for _, v := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+func canonicalQuery(req *http.Request) string {
+	q := req.URL.Query()
+	var keys []string
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		vs := q[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes the characters except the unreserved ones, and the slashes of the paths
+func uriEncode(v string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(v) {
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hexSHA256(v string) string {
+	h := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, v string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(v))
+	return h.Sum(nil)
+}
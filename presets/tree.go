@@ -0,0 +1,338 @@
+package presets
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
+	h "github.com/theplant/htmlgo"
+)
+
+const treePortalName = "tree"
+const treeNodeFieldName = "treeNode"
+const treeParentFieldName = "treeParent"
+
+// treeNewParentFieldName is the hidden field of the parent of the records created from the nodes, instead of
+// an event param that the other events of the form like adding has-many rows use for their own
+const treeNewParentFieldName = "__TreeParent"
+
+// treeMaxDepth stops walking up the parents of the records that already have cycles
+const treeMaxDepth = 1000
+
+type TreeNodeTitleFunc func(obj interface{}, ctx *web.EventContext) string
+
+type TreeBuilder struct {
+	lb            *ListingBuilder
+	parentField   string
+	nodeTitleFunc TreeNodeTitleFunc
+	isDefault     bool
+}
+
+// Tree adds a tree layout to the listing for the models that reference their parents by the field,
+// records whose parent is zero or null are the roots. children are loaded when the nodes are opened,
+// created with the parent id as the second param of the new drawer event, and moved by dragging them to other nodes
+func (b *ListingBuilder) Tree(parentField string) (r *TreeBuilder) {
+	if b.tree == nil || b.tree.parentField != parentField {
+		b.tree = &TreeBuilder{lb: b, parentField: parentField}
+	}
+	return b.tree
+}
+
+// NodeTitleFunc returns the names of the nodes, defaults to the page title of the record
+func (b *TreeBuilder) NodeTitleFunc(v TreeNodeTitleFunc) (r *TreeBuilder) {
+	b.nodeTitleFunc = v
+	return b
+}
+
+// Default shows the tree instead of the table when the layout is not chosen in url
+func (b *TreeBuilder) Default(v bool) (r *TreeBuilder) {
+	b.isDefault = v
+	return b
+}
+
+type treeNode struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Movable bool   `json:"movable"`
+	// empty children makes the node load them when it's opened, nil is a leaf
+	Children []*treeNode `json:"children"`
+}
+
+// parentID returns empty for the roots
+func (b *TreeBuilder) parentID(obj interface{}) string {
+	v := reflect.ValueOf(reflectutils.MustGet(obj, b.parentField))
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.IsZero() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func (b *TreeBuilder) rootCondition() *SQLCondition {
+	column := sortKey(b.parentField)
+	t := reflectutils.GetType(b.lb.mb.model, b.parentField)
+	if t == nil || t.Kind() == reflect.Ptr {
+		return &SQLCondition{Query: fmt.Sprintf("%s IS NULL", column)}
+	}
+	return &SQLCondition{
+		Query: fmt.Sprintf("(%s IS NULL OR %s = ?)", column, column),
+		Args:  []interface{}{reflect.Zero(t).Interface()},
+	}
+}
+
+// nodes searches the children of the parent with the listing search, or the roots when parentID is empty
+func (b *TreeBuilder) nodes(parentID string, ctx *web.EventContext) (r []*treeNode, err error) {
+	searchParams, _ := b.lb.searchParamsFromQuery(ctx)
+	searchParams.PerPage = 0
+	searchParams.Page = 0
+	conds := append([]*SQLCondition{}, searchParams.SQLConditions...)
+
+	cond := b.rootCondition()
+	if len(parentID) > 0 {
+		cond = &SQLCondition{
+			Query: fmt.Sprintf("%s = ?", sortKey(b.parentField)),
+			Args:  []interface{}{parentID},
+		}
+	}
+	searchParams.SQLConditions = append(conds, cond)
+
+	objs, _, err := b.lb.searcher(b.lb.mb.newModelArray(), searchParams, ctx)
+	if err != nil {
+		return
+	}
+
+	rv := reflect.ValueOf(objs)
+	var ids []string
+	for i := 0; i < rv.Len(); i++ {
+		obj := rv.Index(i).Interface()
+		id := b.lb.mb.objectID(obj)
+		ids = append(ids, id)
+
+		node := &treeNode{
+			ID:      id,
+			Movable: b.lb.mb.Info().Verifier().Do(PermUpdate).ObjectOn(obj).SnakeOn(b.parentField).WithReq(ctx.R).IsAllowed() == nil,
+		}
+		if b.nodeTitleFunc != nil {
+			node.Name = b.nodeTitleFunc(obj, ctx)
+		} else {
+			node.Name = getPageTitle(obj, id)
+		}
+		r = append(r, node)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	// without an aggregator to count the children, all the nodes can be opened to load them
+	if b.lb.aggregator == nil {
+		for _, n := range r {
+			n.Children = []*treeNode{}
+		}
+		return
+	}
+
+	// counts the children of all the nodes in one query, so that only the nodes with children can be opened
+	searchParams.SQLConditions = append(conds, &SQLCondition{
+		Query: fmt.Sprintf("%s IN (?)", sortKey(b.parentField)),
+		Args:  []interface{}{ids},
+	})
+	counts, err := b.lb.aggregator(b.lb.mb.newModel(), searchParams, &AggregateParams{
+		Aggregates: []*Aggregate{{Function: AggregateCount}},
+		GroupBy:    sortKey(b.parentField),
+	}, ctx)
+	if err != nil {
+		return
+	}
+
+	hasChildren := map[string]bool{}
+	for _, c := range counts {
+		hasChildren[fmt.Sprint(c.Group)] = true
+	}
+	for _, n := range r {
+		if hasChildren[n.ID] {
+			n.Children = []*treeNode{}
+		}
+	}
+	return
+}
+
+func (b *TreeBuilder) tree(msgr *Messages, alert h.HTMLComponent, ctx *web.EventContext) h.HTMLComponent {
+	roots, err := b.nodes("", ctx)
+	if err != nil {
+		panic(err)
+	}
+	if roots == nil {
+		roots = []*treeNode{}
+	}
+
+	loadChildren := fmt.Sprintf("(item) => $plaid().vars(vars).eventFunc(%s, String(item.id)).go().then((r) => { item.children.push(...(r.data || [])) })",
+		h.JSONString(actions.LoadTreeChildren))
+
+	moveTo := func(parent string) string {
+		return web.Plaid().
+			EventFunc(actions.MoveTreeNode).
+			FieldValue(treeNodeFieldName, web.Var("vars.treeNode")).
+			FieldValue(treeParentFieldName, web.Var(parent)).
+			Go()
+	}
+
+	var open string
+	if b.lb.mb.hasDetailing {
+		open = web.Plaid().Raw(fmt.Sprintf("pushStateURL(%s + '/' + item.id)", h.JSONString(b.lb.mb.Info().ListingHref()))).Go()
+	} else {
+		open = web.Plaid().Raw(fmt.Sprintf("eventFunc(%s, String(item.id))", h.JSONString(actions.DrawerEdit))).Go()
+	}
+
+	var addChild h.HTMLComponent
+	if b.lb.mb.Info().Verifier().Do(PermCreate).WithReq(ctx.R).IsAllowed() == nil {
+		addChild = VBtn("").Icon(true).Small(true).Children(VIcon("add").Small(true)).
+			Attr("title", msgr.TreeAddChild).
+			Attr("@click.stop", web.Plaid().
+				EventFunc(actions.DrawerNew, "").
+				FieldValue(treeNewParentFieldName, web.Var("String(item.id)")).
+				Go())
+	}
+
+	return h.Div(
+		alert,
+		VCard(
+			VCardText(
+				h.Div(h.Text(msgr.TreeMoveToRoot)).
+					Class("caption grey--text text-center pa-2 mb-2").
+					Style("border: 1px dashed #ccc;").
+					Attr("@dragover.prevent", "").
+					Attr("@drop.prevent", moveTo("''")),
+				VTreeview(
+					web.Slot(
+						h.Span("{{ item.name }}").
+							Style("cursor: pointer;").
+							Attr(":draggable", "item.movable").
+							Attr("@dragstart", "vars.treeNode = item.id").
+							Attr("@dragover.prevent", "").
+							Attr("@drop.prevent", moveTo("item.id")).
+							Attr("@click", open),
+					).Name("label").Scope("{ item }"),
+					web.Slot(
+						addChild,
+					).Name("append").Scope("{ item }"),
+				).Items(roots).
+					ItemKey("id").
+					ItemText("name").
+					Hoverable(true).
+					Dense(true).
+					Attr(":load-children", loadChildren),
+			),
+		).Flat(true),
+	).Attr(web.InitContextVars, `{treeNode: ""}`)
+}
+
+func (b *TreeBuilder) loadTreeChildren(ctx *web.EventContext) (r web.EventResponse, err error) {
+	parentID := ctx.Event.Params[0]
+	if len(parentID) == 0 {
+		err = errors.New("parent required")
+		return
+	}
+
+	nodes, err := b.nodes(parentID, ctx)
+	if err != nil {
+		return
+	}
+	if nodes == nil {
+		nodes = []*treeNode{}
+	}
+	r.Data = nodes
+	return
+}
+
+// newParentInput carries the parent of the new record in the form, that's set by the add button of the nodes
+func (b *TreeBuilder) newParentInput(id string, ctx *web.EventContext) h.HTMLComponent {
+	parentID := ctx.R.FormValue(treeNewParentFieldName)
+	if b == nil || len(id) > 0 || len(parentID) == 0 {
+		return nil
+	}
+	return h.Input("").Type("hidden").
+		Value(parentID).
+		Attr(web.VFieldName(treeNewParentFieldName)...)
+}
+
+// setNewParent sets the parent of the records created from the nodes, that is in the form from newParentInput
+func (b *TreeBuilder) setNewParent(obj interface{}, ctx *web.EventContext) (err error) {
+	parentID := ctx.R.FormValue(treeNewParentFieldName)
+	if b == nil || len(parentID) == 0 {
+		return
+	}
+
+	_, err = b.lb.mb.editing.fetcher(b.lb.mb.newModel(), parentID, ctx)
+	if err != nil {
+		return
+	}
+	return formContext(url.Values{b.parentField: []string{parentID}}, ctx).UnmarshalForm(obj)
+}
+
+// isDescendant checks if the parent is the node or under it, that moving the node to it makes a cycle
+func (b *TreeBuilder) isDescendant(parentID string, nodeID string, ctx *web.EventContext) (r bool, err error) {
+	for i := 0; len(parentID) > 0 && i < treeMaxDepth; i++ {
+		if parentID == nodeID {
+			return true, nil
+		}
+
+		var obj interface{}
+		obj, err = b.lb.mb.editing.fetcher(b.lb.mb.newModel(), parentID, ctx)
+		if err != nil {
+			return
+		}
+		parentID = b.parentID(obj)
+	}
+	return len(parentID) > 0, nil
+}
+
+// moveTreeNode sets the parent of the dragged node with the editing form, and renders the tree again
+// with the error if it fails
+func (b *TreeBuilder) moveTreeNode(ctx *web.EventContext) (r web.EventResponse, err error) {
+	msgr := MustGetMessages(ctx.R)
+	id := ctx.R.FormValue(treeNodeFieldName)
+	parentID := ctx.R.FormValue(treeParentFieldName)
+	if len(id) == 0 {
+		err = errors.New("tree node required")
+		return
+	}
+
+	err1 := b.move(id, parentID, msgr, ctx)
+
+	var alert h.HTMLComponent
+	if err1 != nil {
+		alert = VAlert(h.Text(fieldErrorMessage(err1, b.parentField))).
+			Dense(true).
+			Type("error").
+			Class("mb-3")
+	}
+
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: treePortalName,
+		Body: b.tree(msgr, alert, ctx),
+	})
+	return
+}
+
+func (b *TreeBuilder) move(id string, parentID string, msgr *Messages, ctx *web.EventContext) (err error) {
+	cycle, err := b.isDescendant(parentID, id, ctx)
+	if err != nil {
+		return
+	}
+	if cycle {
+		return errors.New(msgr.TreeCycleError)
+	}
+
+	_, err = b.lb.updateFields(b.lb.inlineEditingBuilder(b.parentField), id, formContext(url.Values{b.parentField: []string{parentID}}, ctx))
+	return
+}
@@ -1,13 +1,71 @@
 package presets
 
 import (
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"strings"
+
 	"github.com/goplaid/web"
 	"github.com/goplaid/x/presets/actions"
 	"github.com/goplaid/x/stripeui"
 	. "github.com/goplaid/x/vuetify"
+	"github.com/sunfmin/reflectutils"
 	h "github.com/theplant/htmlgo"
 )
 
+type primarySlugger interface {
+	PrimarySlug() string
+}
+
+// objectID returns the id of obj that is used in urls and event params, the same as the listing data table
+func (b *ModelBuilder) objectID(obj interface{}) string {
+	if slugger, ok := obj.(primarySlugger); ok {
+		return slugger.PrimarySlug()
+	}
+	return fmt.Sprint(reflectutils.MustGet(obj, b.primaryField))
+}
+
+// formContext builds a context whose submitted form is values, for running the editing pipeline
+// with values that are not from the editing form
+func formContext(values url.Values, ctx *web.EventContext) *web.EventContext {
+	r := ctx.R.Clone(ctx.R.Context())
+	r.Form = values
+	r.PostForm = values
+	r.MultipartForm = &multipart.Form{Value: values, File: map[string][]*multipart.FileHeader{}}
+
+	return &web.EventContext{
+		R:        r,
+		W:        ctx.W,
+		Hub:      ctx.Hub,
+		Injector: ctx.Injector,
+		Event:    &web.Event{Params: []string{""}},
+	}
+}
+
+// fieldErrorMessage joins the global errors and the errors of the field, to show the error of updating the field
+// out of the form
+func fieldErrorMessage(err error, fieldName string) string {
+	vErr, ok := err.(*web.ValidationErrors)
+	if !ok {
+		return err.Error()
+	}
+
+	msgs := append(vErr.GetGlobalErrors(), vErr.GetFieldErrors(fieldName)...)
+	if len(msgs) == 0 {
+		return vErr.Error()
+	}
+	return strings.Join(msgs, ", ")
+}
+
+// tdToDiv renders the td of listing cell component funcs as a div, to show them out of the table
+func tdToDiv(cell h.HTMLComponent) h.HTMLComponent {
+	if td, ok := cell.(*h.HTMLTagBuilder); ok {
+		return td.Tag("div")
+	}
+	return cell
+}
+
 func EditDeleteRowMenuItemsFunc(m *ModelInfo, url string, editExtraParams ...string) stripeui.RowMenuItemsFunc {
 	return func(obj interface{}, id string, ctx *web.EventContext) []h.HTMLComponent {
 		msgr := MustGetMessages(ctx.R)
@@ -0,0 +1,187 @@
+package presets
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// validateTagName is specific to presets, that the rules don't collide with the validate tag of other validators
+const validateTagName = "presets"
+
+var emailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+type validateRule struct {
+	name  string
+	param string
+	// limit is the number param of min and max
+	limit float64
+}
+
+// validateRulesOfTypes caches the rules of the fields by the struct types
+var validateRulesOfTypes sync.Map
+
+// structValidateRules parses the tags like `presets:"required,email,max=200"` of the fields of the struct once,
+// the unknown rules and the rules with invalid params are logged and skipped, that they don't fail the requests
+func structValidateRules(t reflect.Type) (r map[string][]*validateRule) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	if v, ok := validateRulesOfTypes.Load(t); ok {
+		return v.(map[string][]*validateRule)
+	}
+
+	r = map[string][]*validateRule{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		for _, s := range strings.Split(sf.Tag.Get(validateTagName), ",") {
+			s = strings.TrimSpace(s)
+			if len(s) == 0 {
+				continue
+			}
+			kv := strings.SplitN(s, "=", 2)
+			rule := &validateRule{name: kv[0]}
+			if len(kv) == 2 {
+				rule.param = kv[1]
+			}
+			if err := rule.parse(sf.Type); err != nil {
+				log.Printf("validate rule %s of field %s of %s skipped: %v\n", s, sf.Name, t, err)
+				continue
+			}
+			r[sf.Name] = append(r[sf.Name], rule)
+		}
+	}
+
+	v, _ := validateRulesOfTypes.LoadOrStore(t, r)
+	return v.(map[string][]*validateRule)
+}
+
+// parse checks the rule is known and its param can be used for the field type
+func (rule *validateRule) parse(fieldType reflect.Type) (err error) {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	switch rule.name {
+	case "required", "email", "oneof":
+		return
+	case "min", "max":
+		rule.limit, err = strconv.ParseFloat(rule.param, 64)
+		if err != nil {
+			return fmt.Errorf("%s requires a number", rule.name)
+		}
+		switch fieldType.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return
+		}
+		return fmt.Errorf("%s can't be used for %s", rule.name, fieldType)
+	}
+	return fmt.Errorf("unknown rule %s", rule.name)
+}
+
+func validateRules(model interface{}, fieldName string) (r []*validateRule) {
+	return structValidateRules(reflect.TypeOf(model))[fieldName]
+}
+
+func isRequired(model interface{}, fieldName string) bool {
+	for _, rule := range validateRules(model, fieldName) {
+		if rule.name == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateField checks the value of the field with the rules in the presets tag, and returns the message of the first failed rule.
+// rules other than required are skipped for empty strings and nil pointers, that the fields are optional unless they are required
+func validateField(msgr *Messages, obj interface{}, fieldName string) (msg string) {
+	rules := validateRules(obj, fieldName)
+	if len(rules) == 0 {
+		return
+	}
+
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	v = v.FieldByName(fieldName)
+
+	empty := v.IsZero()
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		empty = len(strings.TrimSpace(v.String())) == 0
+	}
+
+	for _, rule := range rules {
+		if rule.name == "required" {
+			if empty {
+				return msgr.ValidateRequired
+			}
+			continue
+		}
+		if empty && (v.Kind() == reflect.String || v.Kind() == reflect.Ptr) {
+			continue
+		}
+
+		switch rule.name {
+		case "email":
+			if !emailRegexp.MatchString(v.String()) {
+				return msgr.ValidateEmail
+			}
+		case "min", "max":
+			if failed, isLength := compareRule(v, rule); failed {
+				return msgr.ValidateLimit(rule.name, rule.param, isLength)
+			}
+		case "oneof":
+			values := strings.Fields(rule.param)
+			found := false
+			for _, value := range values {
+				if fmt.Sprint(v.Interface()) == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return msgr.ValidateOneOf(strings.Join(values, ", "))
+			}
+		}
+	}
+	return
+}
+
+// compareRule compares the length of strings and slices, or the numbers with the param of min and max,
+// isLength is for the messages of the number of characters
+func compareRule(v reflect.Value, rule *validateRule) (failed bool, isLength bool) {
+	var n float64
+	switch v.Kind() {
+	case reflect.String:
+		isLength = true
+		n = float64(utf8.RuneCountInString(v.String()))
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n = float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	}
+
+	if rule.name == "min" {
+		return n < rule.limit, isLength
+	}
+	return n > rule.limit, isLength
+}
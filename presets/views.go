@@ -0,0 +1,236 @@
+package presets
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/goplaid/web"
+	"github.com/goplaid/x/presets/actions"
+	. "github.com/goplaid/x/vuetify"
+	h "github.com/theplant/htmlgo"
+)
+
+// ListingView is a listing state saved by a user, Query is the url query of the listing
+// that has the keyword, filters, order and columns
+type ListingView struct {
+	UserID string
+	Model  string
+	Name   string
+	Query  string
+}
+
+// ListingViewStore stores the saved listing views, views are unique by user, model and name
+type ListingViewStore interface {
+	ListViews(userID string, model string) (r []*ListingView, err error)
+	SaveView(v *ListingView) (err error)
+	DeleteView(userID string, model string, name string) (err error)
+}
+
+type UserIDFunc func(r *http.Request) string
+
+// ListingViewStore enables saving listing views for the users identified by UserIDFunc
+func (b *Builder) ListingViewStore(v ListingViewStore) (r *Builder) {
+	b.listingViewStore = v
+	return b
+}
+
+func (b *Builder) UserIDFunc(v UserIDFunc) (r *Builder) {
+	b.userIDFunc = v
+	return b
+}
+
+type MemoryListingViewStore struct {
+	mu    sync.RWMutex
+	views map[string][]*ListingView
+}
+
+func NewMemoryListingViewStore() (r *MemoryListingViewStore) {
+	return &MemoryListingViewStore{views: map[string][]*ListingView{}}
+}
+
+func (s *MemoryListingViewStore) ListViews(userID string, model string) (r []*ListingView, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.views[userID+"/"+model] {
+		vc := *v
+		r = append(r, &vc)
+	}
+	return
+}
+
+func (s *MemoryListingViewStore) SaveView(v *ListingView) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := v.UserID + "/" + v.Model
+	vc := *v
+	for i, ev := range s.views[key] {
+		if ev.Name == v.Name {
+			s.views[key][i] = &vc
+			return
+		}
+	}
+	s.views[key] = append(s.views[key], &vc)
+	return
+}
+
+func (s *MemoryListingViewStore) DeleteView(userID string, model string, name string) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := userID + "/" + model
+	var views []*ListingView
+	for _, v := range s.views[key] {
+		if v.Name != name {
+			views = append(views, v)
+		}
+	}
+	s.views[key] = views
+	return
+}
+
+const saveViewPortalName = "saveView"
+const viewNameFieldName = "ViewName"
+
+// viewQueryIgnoredParams are the url params that are not part of a listing view
+var viewQueryIgnoredParams = []string{"page", cursorParamName, selectedParamName, bulkPanelOpenParamName}
+
+func (b *ListingBuilder) viewUserID(r *http.Request) string {
+	if b.mb.p.listingViewStore == nil {
+		return ""
+	}
+	return b.mb.p.userID(r)
+}
+
+// viewQuery normalizes the listing query of a view, so that views can be compared with the current url
+func viewQuery(rawQuery string) string {
+	q, _ := url.ParseQuery(rawQuery)
+	for _, p := range viewQueryIgnoredParams {
+		q.Del(p)
+	}
+	return q.Encode()
+}
+
+func (b *ListingBuilder) listingViews(ctx *web.EventContext) (r []*ListingView) {
+	userID := b.viewUserID(ctx.R)
+	if len(userID) == 0 {
+		return
+	}
+
+	r, err := b.mb.p.listingViewStore.ListViews(userID, b.mb.uriName)
+	if err != nil {
+		panic(err)
+	}
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Name < r[j].Name
+	})
+	return
+}
+
+func (b *ListingBuilder) saveViewButton(msgr *Messages, ctx *web.EventContext) h.HTMLComponent {
+	if len(b.viewUserID(ctx.R)) == 0 {
+		return nil
+	}
+
+	return h.Components(
+		web.Portal().Name(saveViewPortalName),
+		VBtn(msgr.SaveView).
+			Depressed(true).
+			Class("ml-2").
+			OnClick(actions.SaveViewDialog, viewQuery(ctx.R.URL.RawQuery)),
+	)
+}
+
+func (b *ListingBuilder) saveViewDialog(ctx *web.EventContext) (r web.EventResponse, err error) {
+	r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+		Name: saveViewPortalName,
+		Body: b.saveViewForm(ctx.Event.Params[0], "", ctx),
+	})
+	r.VarsScript = "setTimeout(function(){ vars.saveView = true }, 100)"
+	return
+}
+
+func (b *ListingBuilder) saveViewForm(query string, nameErr string, ctx *web.EventContext) h.HTMLComponent {
+	msgr := MustGetMessages(ctx.R)
+
+	var errs []string
+	if len(nameErr) > 0 {
+		errs = append(errs, nameErr)
+	}
+
+	return VDialog(
+		VCard(
+			VCardTitle(h.Text(msgr.SaveView)),
+			VCardText(
+				VTextField().
+					FieldName(viewNameFieldName).
+					Label(msgr.ViewName).
+					ErrorMessages(errs...),
+			),
+			VCardActions(
+				VSpacer(),
+				VBtn(msgr.Cancel).
+					Depressed(true).
+					Class("ml-2").
+					On("click", "vars.saveView = false"),
+
+				VBtn(msgr.OK).
+					Color("primary").
+					Depressed(true).
+					Dark(true).
+					Attr("@click", web.Plaid().
+						EventFunc(actions.DoSaveView, query).
+						URL(ctx.R.URL.Path).
+						Go()),
+			),
+		),
+	).MaxWidth("600px").
+		Attr("v-model", "vars.saveView").
+		Attr(web.InitContextVars, `{saveView: false}`)
+}
+
+func (b *ListingBuilder) doSaveView(ctx *web.EventContext) (r web.EventResponse, err error) {
+	userID := b.viewUserID(ctx.R)
+	if len(userID) == 0 {
+		return
+	}
+
+	query := ctx.Event.Params[0]
+	name := ctx.R.FormValue(viewNameFieldName)
+	if len(name) == 0 {
+		r.UpdatePortals = append(r.UpdatePortals, &web.PortalUpdate{
+			Name: saveViewPortalName,
+			Body: b.saveViewForm(query, MustGetMessages(ctx.R).ViewNameRequired, ctx),
+		})
+		r.VarsScript = "setTimeout(function(){ vars.saveView = true }, 100)"
+		return
+	}
+
+	err = b.mb.p.listingViewStore.SaveView(&ListingView{
+		UserID: userID,
+		Model:  b.mb.uriName,
+		Name:   name,
+		Query:  viewQuery(query),
+	})
+	if err != nil {
+		return
+	}
+
+	r.PushState = web.PushState(nil)
+	return
+}
+
+func (b *ListingBuilder) doDeleteView(ctx *web.EventContext) (r web.EventResponse, err error) {
+	userID := b.viewUserID(ctx.R)
+	if len(userID) == 0 {
+		return
+	}
+
+	err = b.mb.p.listingViewStore.DeleteView(userID, b.mb.uriName, ctx.Event.Params[0])
+	if err != nil {
+		return
+	}
+
+	r.PushState = web.PushState(nil)
+	return
+}
@@ -0,0 +1,118 @@
+package presets
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xlsxWriter streams rows into a single sheet xlsx file, cells are written as inline strings
+// or numbers so that no shared strings table need to be kept in memory
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	row   int
+	err   error
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="{name}" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
+
+func newXLSXWriter(w io.Writer, sheetName string) (r *xlsxWriter) {
+	r = &xlsxWriter{zw: zip.NewWriter(w)}
+
+	// excel doesn't allow these chars and more than 31 chars in sheet names
+	sheetName = strings.NewReplacer(":", "", "\\", "", "/", "", "?", "", "*", "", "[", "", "]", "").Replace(sheetName)
+	if len([]rune(sheetName)) > 31 {
+		sheetName = string([]rune(sheetName)[:31])
+	}
+	var name strings.Builder
+	_ = xml.EscapeText(&name, []byte(sheetName))
+
+	for _, f := range []struct {
+		path string
+		body string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", strings.Replace(xlsxWorkbook, "{name}", name.String(), 1)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		r.writeFile(f.path, f.body)
+	}
+
+	if r.err != nil {
+		return
+	}
+	r.sheet, r.err = r.zw.Create("xl/worksheets/sheet1.xml")
+	r.write(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return
+}
+
+func (w *xlsxWriter) writeFile(path string, body string) {
+	if w.err != nil {
+		return
+	}
+	var f io.Writer
+	f, w.err = w.zw.Create(path)
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(f, body)
+}
+
+func (w *xlsxWriter) write(s string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(w.sheet, s)
+}
+
+// Write writes one row, numbers are kept as numbers and everything else as strings
+func (w *xlsxWriter) Write(cells []interface{}) error {
+	w.row++
+	w.write(fmt.Sprintf(`<row r="%d">`, w.row))
+	for _, c := range cells {
+		switch c.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			w.write(fmt.Sprintf(`<c t="n"><v>%v</v></c>`, c))
+		default:
+			var v strings.Builder
+			_ = xml.EscapeText(&v, []byte(fmt.Sprint(c)))
+			w.write(fmt.Sprintf(`<c t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, v.String()))
+		}
+	}
+	w.write(`</row>`)
+	return w.err
+}
+
+func (w *xlsxWriter) Close() error {
+	w.write(`</sheetData></worksheet>`)
+	if w.err != nil {
+		return w.err
+	}
+	return w.zw.Close()
+}
@@ -3,6 +3,7 @@ package stripeui
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/goplaid/web"
@@ -18,12 +19,15 @@ type CellComponentFunc func(obj interface{}, fieldName string, ctx *web.EventCon
 type CellWrapperFunc func(cell h.MutableAttrHTMLComponent, id string) h.HTMLComponent
 type RowMenuItemsFunc func(obj interface{}, id string, ctx *web.EventContext) []h.HTMLComponent
 type RowComponentFunc func(obj interface{}, ctx *web.EventContext) h.HTMLComponent
+type GroupCellFunc func(group interface{}, columnName string, ctx *web.EventContext) h.HTMLComponent
+type FootCellFunc func(columnName string, ctx *web.EventContext) h.HTMLComponent
 
 type DataTableBuilder struct {
 	data               interface{}
 	selectable         bool
 	withoutHeaders     bool
 	selectionParamName string
+	orderByParamName   string
 	cellWrapper        CellWrapperFunc
 	rowMenuItemsFunc   RowMenuItemsFunc
 	rowExpandFunc      RowComponentFunc
@@ -31,12 +35,16 @@ type DataTableBuilder struct {
 	loadMoreCount      int
 	loadMoreLabel      string
 	loadMoreURL        string
+	groupByField       string
+	groupCellFunc      GroupCellFunc
+	footCellFunc       FootCellFunc
 }
 
 func DataTable(data interface{}) (r *DataTableBuilder) {
 	r = &DataTableBuilder{
 		data:               data,
 		selectionParamName: "selected",
+		orderByParamName:   "order_by",
 	}
 	return
 }
@@ -67,6 +75,11 @@ func (b *DataTableBuilder) SelectionParamName(v string) (r *DataTableBuilder) {
 	return b
 }
 
+func (b *DataTableBuilder) OrderByParamName(v string) (r *DataTableBuilder) {
+	b.orderByParamName = v
+	return b
+}
+
 func (b *DataTableBuilder) WithoutHeader(v bool) (r *DataTableBuilder) {
 	b.withoutHeaders = v
 	return b
@@ -87,6 +100,20 @@ func (b *DataTableBuilder) RowExpandFunc(v RowComponentFunc) (r *DataTableBuilde
 	return b
 }
 
+// GroupBy inserts a group header row before the records whose value of the field differs from the previous record,
+// with the cells that groupCellFunc returns for every column, so the data should be ordered by the field
+func (b *DataTableBuilder) GroupBy(fieldName string, groupCellFunc GroupCellFunc) (r *DataTableBuilder) {
+	b.groupByField = fieldName
+	b.groupCellFunc = groupCellFunc
+	return b
+}
+
+// FootCellFunc adds a footer row with the cells it returns for every column, like the totals of the columns
+func (b *DataTableBuilder) FootCellFunc(v FootCellFunc) (r *DataTableBuilder) {
+	b.footCellFunc = v
+	return b
+}
+
 type primarySlugger interface {
 	PrimarySlug() string
 }
@@ -114,6 +141,7 @@ func (b *DataTableBuilder) MarshalHTML(c context.Context) (r []byte, err error)
 	i := 0
 	tdCount := 0
 	haveMoreRecord := false
+	var lastGroup *string
 	funk.ForEach(b.data, func(obj interface{}) {
 
 		var id string
@@ -199,6 +227,21 @@ func (b *DataTableBuilder) MarshalHTML(c context.Context) (r []byte, err error)
 			haveMoreRecord = true
 		}
 
+		if len(b.groupByField) > 0 {
+			group := reflectutils.MustGet(obj, b.groupByField)
+			if lastGroup == nil || *lastGroup != fmt.Sprint(group) {
+				key := fmt.Sprint(group)
+				lastGroup = &key
+				groupRow := b.extraRow(func(columnName string) h.HTMLComponent {
+					return b.groupCellFunc(group, columnName, ctx)
+				}, hasExpand, haveRowMenus).Class("grey lighten-4")
+				if haveMoreRecord {
+					groupRow.Attr("v-if", fmt.Sprintf("vars.%s", loadMoreVarName))
+				}
+				rows = append(rows, groupRow)
+			}
+		}
+
 		rows = append(rows, row)
 
 		if hasExpand {
@@ -247,8 +290,13 @@ func (b *DataTableBuilder) MarshalHTML(c context.Context) (r []byte, err error)
 			).Style("width: 48px;").Class("pr-0"))
 		}
 
+		orderKey, orderDesc := getOrderBy(ctx, b.orderByParamName)
 		for _, f := range b.columns {
-			heads = append(heads, h.Th(f.title))
+			if !f.sortable {
+				heads = append(heads, h.Th(f.title))
+				continue
+			}
+			heads = append(heads, b.sortableTh(f, orderKey, orderDesc))
 		}
 
 		if haveRowMenus {
@@ -259,7 +307,13 @@ func (b *DataTableBuilder) MarshalHTML(c context.Context) (r []byte, err error)
 		).Class("grey lighten-5")
 	}
 
-	var tfoot h.HTMLComponent
+	var footRows []h.HTMLComponent
+	if b.footCellFunc != nil {
+		footRows = append(footRows, b.extraRow(func(columnName string) h.HTMLComponent {
+			return b.footCellFunc(columnName, ctx)
+		}, hasExpand, haveRowMenus))
+	}
+
 	if b.loadMoreCount > 0 && haveMoreRecord {
 		var btn h.HTMLComponent
 
@@ -279,14 +333,17 @@ func (b *DataTableBuilder) MarshalHTML(c context.Context) (r []byte, err error)
 				Href(b.loadMoreURL)
 		}
 
-		tfoot = h.Tfoot(
-			h.Tr(
-				h.Td(
-					h.If(!hasExpand, VDivider()),
-					btn,
-				).Class("text-center pa-0").Attr("colspan", fmt.Sprint(tdCount)),
-			),
-		).Attr("v-if", fmt.Sprintf("!vars.%s", loadMoreVarName))
+		footRows = append(footRows, h.Tr(
+			h.Td(
+				h.If(!hasExpand, VDivider()),
+				btn,
+			).Class("text-center pa-0").Attr("colspan", fmt.Sprint(tdCount)),
+		).Attr("v-if", fmt.Sprintf("!vars.%s", loadMoreVarName)))
+	}
+
+	var tfoot h.HTMLComponent
+	if len(footRows) > 0 {
+		tfoot = h.Tfoot(footRows...)
 	}
 
 	table := VSimpleTable(
@@ -306,6 +363,24 @@ func (b *DataTableBuilder) MarshalHTML(c context.Context) (r []byte, err error)
 	return table.MarshalHTML(c)
 }
 
+// extraRow renders the group header and footer rows, with empty cells for the expand, checkbox and menu columns
+func (b *DataTableBuilder) extraRow(cellFunc func(columnName string) h.HTMLComponent, hasExpand bool, haveRowMenus bool) (r *h.HTMLTagBuilder) {
+	var tds []h.HTMLComponent
+	if hasExpand {
+		tds = append(tds, h.Td())
+	}
+	if b.selectable {
+		tds = append(tds, h.Td())
+	}
+	for _, f := range b.columns {
+		tds = append(tds, cellFunc(f.name))
+	}
+	if haveRowMenus {
+		tds = append(tds, h.Td())
+	}
+	return h.Tr(tds...)
+}
+
 func getSelectedIds(ctx *web.EventContext, selectedParamName string) (selected []string) {
 	selectedValue := ctx.R.URL.Query().Get(selectedParamName)
 	if len(selectedValue) > 0 {
@@ -314,6 +389,44 @@ func getSelectedIds(ctx *web.EventContext, selectedParamName string) (selected [
 	return selected
 }
 
+// getOrderBy parses values like name_desc, name_asc or name from the url
+func getOrderBy(ctx *web.EventContext, orderByParamName string) (key string, desc bool) {
+	key = ctx.R.URL.Query().Get(orderByParamName)
+	if strings.HasSuffix(key, "_desc") {
+		return strings.TrimSuffix(key, "_desc"), true
+	}
+	return strings.TrimSuffix(key, "_asc"), false
+}
+
+func (b *DataTableBuilder) sortableTh(f *DataTableColumnBuilder, orderKey string, orderDesc bool) h.HTMLComponent {
+	key := f.sortKey
+	if len(key) == 0 {
+		key = f.name
+	}
+
+	icon := "arrow_upward"
+	iconClass := "grey--text text--lighten-1"
+	next := key + "_asc"
+	if key == orderKey {
+		iconClass = ""
+		if orderDesc {
+			icon = "arrow_downward"
+		} else {
+			next = key + "_desc"
+		}
+	}
+
+	return h.Th("").Children(
+		h.A(
+			h.Text(f.title),
+			VIcon(icon).Small(true).Class("ml-1", iconClass),
+		).Attr("@click", web.Plaid().
+			PushStateQuery(url.Values{b.orderByParamName: []string{next}}).
+			MergeQuery(true).
+			Go()),
+	).Style("white-space: nowrap;")
+}
+
 func allSelected(selectedInURL []string, pageSelected []string) bool {
 	for _, ps := range pageSelected {
 		if !funk.ContainsString(selectedInURL, ps) {
@@ -343,6 +456,8 @@ func defaultCellComponentFunc(obj interface{}, fieldName string, ctx *web.EventC
 type DataTableColumnBuilder struct {
 	name              string
 	title             string
+	sortable          bool
+	sortKey           string
 	cellComponentFunc CellComponentFunc
 }
 
@@ -356,6 +471,17 @@ func (b *DataTableColumnBuilder) Title(v string) (r *DataTableColumnBuilder) {
 	return b
 }
 
+func (b *DataTableColumnBuilder) Sortable(v bool) (r *DataTableColumnBuilder) {
+	b.sortable = v
+	return b
+}
+
+// SortKey is the value put into the order by url param, defaults to the column name
+func (b *DataTableColumnBuilder) SortKey(v string) (r *DataTableColumnBuilder) {
+	b.sortKey = v
+	return b
+}
+
 func (b *DataTableColumnBuilder) CellComponentFunc(v CellComponentFunc) (r *DataTableColumnBuilder) {
 	b.cellComponentFunc = v
 	return b